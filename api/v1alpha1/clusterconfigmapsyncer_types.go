@@ -0,0 +1,55 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ClusterConfigMapSyncer is the Schema for the clusterconfigmapsyncers API. It is the
+// cluster-scoped counterpart of ConfigMapSyncer for platform admins and may read its
+// master ConfigMap from any namespace.
+// +kubebuilder:resource:scope=Cluster,shortName=ccms
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Targets",type="integer",JSONPath=".status.targetsTotal"
+// +kubebuilder:printcolumn:name="Synced",type="integer",JSONPath=".status.targetsSynced"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.targetsFailed"
+// +kubebuilder:printcolumn:name="Master Version",type="string",JSONPath=".status.masterResourceVersion",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ClusterConfigMapSyncer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConfigMapSyncerSpec   `json:"spec,omitempty"`
+	Status ConfigMapSyncerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterConfigMapSyncerList contains a list of ClusterConfigMapSyncer.
+type ClusterConfigMapSyncerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterConfigMapSyncer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterConfigMapSyncer{}, &ClusterConfigMapSyncerList{})
+}
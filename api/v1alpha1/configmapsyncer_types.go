@@ -24,6 +24,9 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ConfigMapSyncerSpec defines the desired state of ConfigMapSyncer.
+// +kubebuilder:validation:XValidation:rule="!has(self.targetSecretType) || (has(self.targetKind) && self.targetKind == 'Secret')",message="targetSecretType may only be set for Secret targets"
+// +kubebuilder:validation:XValidation:rule="!has(self.immutableTargets) || !has(self.targetSelector)",message="immutableTargets cannot be combined with targetSelector"
+// +kubebuilder:validation:XValidation:rule="!has(self.immutableTargets) || size(has(self.targetConfigMapName) ? self.targetConfigMapName : self.masterConfigMap.name) <= 242",message="the name of immutable targets may be at most 242 characters since their versions append a content hash"
 type ConfigMapSyncerSpec struct {
 	// MasterConfigMap is the reference to the source ConfigMap that will be propagated
 	// +kubebuilder:validation:Required
@@ -32,8 +35,22 @@ type ConfigMapSyncerSpec struct {
 	// TargetConfigMapName is the name to use for target ConfigMaps
 	// If not specified, the name of the master ConfigMap will be used
 	// +optional
+	// +kubebuilder:validation:MaxLength=253
 	TargetConfigMapName string `json:"targetConfigMapName,omitempty"`
 
+	// TargetKind is the kind of the targets. Secret targets receive every key
+	// of the master as Secret data.
+	// +optional
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default=ConfigMap
+	TargetKind string `json:"targetKind,omitempty"`
+
+	// TargetSecretType is the type of Secret targets created by the controller,
+	// Opaque if not specified. The type of an existing Secret cannot be changed.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	TargetSecretType string `json:"targetSecretType,omitempty"`
+
 	// TargetNamespaces is a list of namespaces where the ConfigMap should be propagated
 	// +optional
 	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
@@ -52,6 +69,366 @@ type ConfigMapSyncerSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=300
 	SyncInterval int32 `json:"syncInterval,omitempty"`
+
+	// PrunePolicy defines what happens to target ConfigMaps that are no longer selected
+	// after a spec change. Delete removes them, Release only removes the labels and
+	// annotations added by the controller and leaves the data in place.
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Release
+	// +kubebuilder:default=Release
+	PrunePolicy string `json:"prunePolicy,omitempty"`
+
+	// StatusReporting configures where the per-target sync statuses are stored
+	// +optional
+	StatusReporting *StatusReporting `json:"statusReporting,omitempty"`
+
+	// Clusters lists remote clusters the master ConfigMap is propagated to in
+	// addition to the local cluster. The same target namespaces and selector apply.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Clusters []ClusterReference `json:"clusters,omitempty"`
+
+	// RestartWorkloads restarts the workloads consuming a target after its
+	// content changed, for consumers that only read it on startup
+	// +optional
+	RestartWorkloads *RestartWorkloads `json:"restartWorkloads,omitempty"`
+
+	// RolloutStrategy propagates a change of the master content to the target
+	// namespaces in waves instead of all at once
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// Canary syncs a change of the master content to canary namespaces first
+	// and only continues once their workloads stayed healthy for the soak
+	// time. The canaries come before the waves of the rollout strategy.
+	// +optional
+	Canary *Canary `json:"canary,omitempty"`
+
+	// Approval is Manual to stage every new master content as a pending
+	// revision that is only propagated once it is approved
+	// +optional
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	// +kubebuilder:default=Automatic
+	Approval string `json:"approval,omitempty"`
+
+	// ApprovedRevision approves the pending revision with the given content hash
+	// or a unique prefix of it. The approved-revision annotation works as well.
+	// +optional
+	// +kubebuilder:validation:MinLength=7
+	ApprovedRevision string `json:"approvedRevision,omitempty"`
+
+	// DryRun plans the changes a sync would make to the targets and reports
+	// them in status.dryRun without writing any target. Writes are sent as
+	// server-side dry runs, so admission rejections show up in the plan.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// SyncWindows restrict when changes are propagated. Outside of them targets
+	// are left as they are until the next window opens.
+	// +optional
+	// +listType=atomic
+	SyncWindows []SyncWindow `json:"syncWindows,omitempty"`
+
+	// ImmutableTargets writes every master content to a new immutable target
+	// named after the target and the content hash instead of updating the target
+	// +optional
+	ImmutableTargets *ImmutableTargets `json:"immutableTargets,omitempty"`
+
+	// RevisionHistoryLimit is the number of master content revisions kept for rollbacks
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// PinnedRevision rolls all targets back to a revision from the history,
+	// given by its content hash or a unique prefix of it. The master is still
+	// read and recorded in the history but not propagated while this is set.
+	// +optional
+	// +kubebuilder:validation:MinLength=7
+	PinnedRevision string `json:"pinnedRevision,omitempty"`
+
+	// Source reads the master content from an external source instead of the
+	// master ConfigMap. The master ConfigMap is then not read, its name and
+	// namespace still name the targets and identify the source.
+	// +optional
+	Source *MasterSource `json:"source,omitempty"`
+}
+
+// RestartWorkloads selects the workloads restarted after a target changed.
+// Deployments, StatefulSets and DaemonSets in the namespace of the target are
+// restarted when their pod template references it through a volume, env or
+// envFrom.
+type RestartWorkloads struct {
+	// Selector restricts the restarted workloads by label. All workloads
+	// referencing the target are restarted when it is not set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// SyncWindow is a time window starting at every activation of a cron schedule
+type SyncWindow struct {
+	// Kind is Allow for the only times changes are propagated, Deny for times
+	// they are not. Deny windows take precedence.
+	// +kubebuilder:validation:Enum=Allow;Deny
+	Kind string `json:"kind"`
+
+	// Schedule is a five-field cron expression for the start of the window
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window lasts, for example 2h or 30m
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone the schedule is evaluated in, UTC if not set
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ImmutableTargets configures versioned targets named <name>-<hash>
+type ImmutableTargets struct {
+	// Keep is the number of versions kept in every namespace, including the current one
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	Keep int32 `json:"keep,omitempty"`
+
+	// Pointer publishes the name of the current version: Annotation annotates
+	// the syncer, AliasConfigMap writes a ConfigMap with the plain target name
+	// holding it into every target namespace
+	// +optional
+	// +kubebuilder:validation:Enum=Annotation;AliasConfigMap
+	// +kubebuilder:default=Annotation
+	Pointer string `json:"pointer,omitempty"`
+}
+
+// Canary names the namespaces a change of the master content is tried in first
+type Canary struct {
+	// Namespaces are the canary target namespaces
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+
+	// SoakSeconds is how long the workloads referencing the canary targets
+	// have to stay ready before the change is propagated further
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300
+	SoakSeconds int32 `json:"soakSeconds,omitempty"`
+}
+
+// RolloutStrategy rolls a new master content out in waves. A wave is only
+// started once the targets of the previous wave are in sync, the workloads
+// restarted for them are ready and the pause has passed. Namespaces that no
+// wave selects form a final wave.
+type RolloutStrategy struct {
+	// Waves in the order they are rolled out
+	// +kubebuilder:validation:MinItems=1
+	Waves []RolloutWave `json:"waves"`
+
+	// PauseSeconds is the time to wait after a wave is healthy before the next one starts
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	PauseSeconds int32 `json:"pauseSeconds,omitempty"`
+
+	// HealthCheck decides when a wave is healthy. WorkloadsReady waits until
+	// the workloads restarted for the targets of the wave are ready, None only
+	// waits for the targets to be in sync.
+	// +optional
+	// +kubebuilder:validation:Enum=WorkloadsReady;None
+	// +kubebuilder:default=WorkloadsReady
+	HealthCheck string `json:"healthCheck,omitempty"`
+
+	// Paused halts the rollout after the current wave
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RolloutWave selects the target namespaces of a wave, either by label or as
+// a percentage of all target namespaces
+// +kubebuilder:validation:XValidation:rule="has(self.namespaceSelector) != has(self.percent)",message="exactly one of namespaceSelector and percent must be set"
+type RolloutWave struct {
+	// Name of the wave shown in the status
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// NamespaceSelector selects the namespaces of the wave by label
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Percent of all target namespaces that have been rolled out once the wave
+	// is done. Namespaces are taken in alphabetical order.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percent *int32 `json:"percent,omitempty"`
+}
+
+// MasterSource is an external source of the master content
+// +kubebuilder:validation:XValidation:rule="[has(self.git), has(self.http), has(self.oci)].filter(x, x).size() == 1",message="exactly one source must be set"
+type MasterSource struct {
+	// Git reads the master content from files in a Git repository
+	// +optional
+	Git *GitSource `json:"git,omitempty"`
+
+	// HTTP reads the master content from a document served over HTTP(S)
+	// +optional
+	HTTP *HTTPSource `json:"http,omitempty"`
+
+	// OCI reads the master content from the files of an OCI artifact
+	// +optional
+	OCI *OCISource `json:"oci,omitempty"`
+}
+
+// OCISource pulls an OCI artifact from a registry. At most one of tag and
+// digest may be set, the tag latest is used otherwise.
+// +kubebuilder:validation:XValidation:rule="!(has(self.tag) && has(self.digest))",message="at most one of tag and digest may be set"
+type OCISource struct {
+	// Repository of the artifact including the registry host, e.g. ghcr.io/example/config
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Repository string `json:"repository"`
+
+	// Tag to pull
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest of the manifest to pull. The pulled manifest is verified against it.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^sha256:[0-9a-f]{64}$`
+	Digest string `json:"digest,omitempty"`
+
+	// Insecure talks to the registry over plain HTTP
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+
+	// SecretRef references a Secret with the registry credentials under the
+	// keys username and password
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+// HTTPSource reads a document from an HTTP(S) endpoint and parses it into keys
+type HTTPSource struct {
+	// URL of the document
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Format of the document. The top-level keys of a JSON or YAML object and
+	// the entries of a properties file become the keys of the master content,
+	// nested values are kept in the format of the document. Raw stores the
+	// whole document under key.
+	// +optional
+	// +kubebuilder:validation:Enum=JSON;YAML;Properties;Raw
+	// +kubebuilder:default=JSON
+	Format string `json:"format,omitempty"`
+
+	// Key the document is stored under with the Raw format
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// SecretRef references a Secret with the credentials for the endpoint,
+	// either a bearer token under the key token or basic auth credentials
+	// under username and password
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+// SecretReference references a Secret
+type SecretReference struct {
+	// Name of the Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the Secret. Defaults to the namespace of the ConfigMapSyncer,
+	// which is also the only namespace a ConfigMapSyncer may use. Required for a
+	// ClusterConfigMapSyncer.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GitSource reads files from a Git repository. At most one of branch, tag and
+// commit may be set, the default branch of the repository is used otherwise.
+// +kubebuilder:validation:XValidation:rule="[has(self.branch), has(self.tag), has(self.commit)].filter(x, x).size() <= 1",message="at most one of branch, tag and commit may be set"
+type GitSource struct {
+	// URL of the repository. file:// URLs and local paths are only allowed for ClusterConfigMapSyncers.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Branch to sync
+	// +optional
+	Branch string `json:"branch,omitempty"`
+
+	// Tag to sync
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Commit to sync
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{7,40}$`
+	Commit string `json:"commit,omitempty"`
+
+	// Path of the directory in the repository whose files become the keys of
+	// the master content. Files in subdirectories are keyed by their path
+	// relative to it with slashes replaced by underscores. Defaults to the
+	// root of the repository.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// ClusterReference identifies a remote cluster by the Secret holding its kubeconfig
+type ClusterReference struct {
+	// Name identifies the cluster in the status
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// KubeconfigSecretRef references the Secret holding the kubeconfig of the cluster
+	// +kubebuilder:validation:Required
+	KubeconfigSecretRef SecretKeyReference `json:"kubeconfigSecretRef"`
+}
+
+// SecretKeyReference references a key of a Secret
+type SecretKeyReference struct {
+	// Name of the Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the Secret. Defaults to the namespace of the ConfigMapSyncer,
+	// which is also the only namespace a ConfigMapSyncer may use. Required for a
+	// ClusterConfigMapSyncer.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key in the Secret data
+	// +optional
+	// +kubebuilder:default=kubeconfig
+	Key string `json:"key,omitempty"`
+}
+
+// StatusReporting configures where the per-target sync statuses are stored
+type StatusReporting struct {
+	// Mode selects where per-target statuses are stored. Inline keeps them in
+	// status.syncStatuses, Report moves them into ConfigMapSyncReport objects and
+	// keeps only aggregates and recent failures in the status, Auto switches to
+	// Report once the number of targets exceeds the threshold.
+	// +optional
+	// +kubebuilder:validation:Enum=Inline;Report;Auto
+	// +kubebuilder:default=Auto
+	Mode string `json:"mode,omitempty"`
+
+	// Threshold is the number of targets above which Auto mode uses reports
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=100
+	Threshold int32 `json:"threshold,omitempty"`
+
+	// MaxFailures is the number of failed targets kept in status.recentFailures in Report mode
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=10
+	MaxFailures *int32 `json:"maxFailures,omitempty"`
 }
 
 // ConfigMapReference contains information to reference a ConfigMap
@@ -63,10 +440,32 @@ type ConfigMapReference struct {
 	// Namespace of the ConfigMap
 	// +kubebuilder:validation:Required
 	Namespace string `json:"namespace"`
+
+	// Kind of the master. A Secret master is only read when the controller
+	// runs with --allow-secret-masters, since it copies Secret data into
+	// targets that may be readable by anyone in their namespace.
+	// +optional
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default=ConfigMap
+	Kind string `json:"kind,omitempty"`
+
+	// KubeconfigSecretRef references the Secret holding the kubeconfig of the
+	// remote cluster the master ConfigMap is read from. The master is read from
+	// the local cluster when it is not set.
+	// +optional
+	KubeconfigSecretRef *SecretKeyReference `json:"kubeconfigSecretRef,omitempty"`
 }
 
 // SyncStatus represents the status of a ConfigMap sync operation
 type SyncStatus struct {
+	// Cluster is the name of the remote cluster of the target, empty for the local cluster
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+
+	// Kind of the target, empty for ConfigMaps
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
 	// ConfigMapName is the name of the target ConfigMap
 	ConfigMapName string `json:"configMapName"`
 
@@ -78,7 +477,7 @@ type SyncStatus struct {
 	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 
 	// Status of the sync operation
-	// +kubebuilder:validation:Enum=Pending;Synced;Failed
+	// +kubebuilder:validation:Enum=Pending;Synced;Failed;Pruned;Forbidden;Skipped
 	Status string `json:"status"`
 
 	// Message provides additional information about the sync status
@@ -99,6 +498,250 @@ type ConfigMapSyncerStatus struct {
 	// LastSyncTime is the timestamp of the last sync attempt
 	// +optional
 	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that was last processed
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// TargetsTotal is the number of target ConfigMaps of the last sync
+	// +optional
+	TargetsTotal int32 `json:"targetsTotal"`
+
+	// TargetsSynced is the number of target ConfigMaps that are in sync
+	// +optional
+	TargetsSynced int32 `json:"targetsSynced"`
+
+	// TargetsFailed is the number of target ConfigMaps that could not be synced
+	// +optional
+	TargetsFailed int32 `json:"targetsFailed"`
+
+	// NamespacesSkipped is the number of target namespaces that opted out of the sync
+	// +optional
+	NamespacesSkipped int32 `json:"namespacesSkipped,omitempty"`
+
+	// MasterResourceVersion is the resourceVersion of the master ConfigMap that was last synced
+	// +optional
+	MasterResourceVersion string `json:"masterResourceVersion,omitempty"`
+
+	// Source reports the revision of the external source that was last synced
+	// +optional
+	Source *SourceStatus `json:"source,omitempty"`
+
+	// RecentFailures holds failed targets of the last sync when the per-target
+	// statuses are stored in reports
+	// +optional
+	RecentFailures []SyncStatus `json:"recentFailures,omitempty"`
+
+	// FailuresOmitted is the number of failed targets that did not fit into recentFailures
+	// +optional
+	FailuresOmitted int32 `json:"failuresOmitted,omitempty"`
+
+	// Reports lists the names of the ConfigMapSyncReports holding the per-target statuses
+	// +optional
+	Reports []string `json:"reports,omitempty"`
+
+	// Rollout reports the progress of the rollout of the master content
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// NextSyncWindow is when the sync windows open next while they are closed
+	// +optional
+	NextSyncWindow *metav1.Time `json:"nextSyncWindow,omitempty"`
+
+	// PendingRevision is the master content waiting for approval
+	// +optional
+	PendingRevision *PendingRevision `json:"pendingRevision,omitempty"`
+
+	// DryRun holds the changes planned by the last dry run
+	// +optional
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+
+	// CurrentRevision is the content hash of the revision propagated to the targets
+	// +optional
+	CurrentRevision string `json:"currentRevision,omitempty"`
+
+	// Revisions lists the revisions of the master content in the history, newest first
+	// +optional
+	Revisions []RevisionStatus `json:"revisions,omitempty"`
+
+	// Clusters reports the health and target counts of each remote cluster
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Clusters []ClusterStatus `json:"clusters,omitempty"`
+}
+
+// PendingRevision describes a master content waiting for approval
+type PendingRevision struct {
+	// Hash is the content hash to approve
+	Hash string `json:"hash"`
+
+	// DetectedTime is when the content was first seen
+	DetectedTime metav1.Time `json:"detectedTime"`
+
+	// Diff lists the keys the content changes compared to the current revision
+	// +optional
+	Diff []KeyDiff `json:"diff,omitempty"`
+}
+
+// DryRunStatus reports the changes a sync would make to the targets
+type DryRunStatus struct {
+	// Time is when the changes were planned
+	Time metav1.Time `json:"time"`
+
+	// Create is the number of targets that would be created
+	Create int32 `json:"create"`
+
+	// Update is the number of targets that would be updated
+	Update int32 `json:"update"`
+
+	// Prune is the number of targets that would be deleted or released
+	Prune int32 `json:"prune"`
+
+	// Skip is the number of targets that would be left as they are
+	Skip int32 `json:"skip"`
+
+	// Rejected is the number of planned writes the API server rejected
+	Rejected int32 `json:"rejected"`
+
+	// Changes lists the planned change of each target, writes and prunes before skipped targets
+	// +optional
+	// +listType=atomic
+	Changes []PlannedChange `json:"changes,omitempty"`
+
+	// Truncated is set when not every target fits into changes
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// PlannedChange describes what a sync would do to a single target
+type PlannedChange struct {
+	// Cluster is the name of the remote cluster of the target, empty for the local cluster
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+
+	// Kind of the target, empty for ConfigMaps
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace is the namespace of the target
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the target, empty when the namespace could not be read
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Action is Create, Update, Prune or Skip
+	// +kubebuilder:validation:Enum=Create;Update;Prune;Skip
+	Action string `json:"action"`
+
+	// Rejected is set when the API server rejected the dry-run write
+	// +optional
+	Rejected bool `json:"rejected,omitempty"`
+
+	// Message tells why a target is skipped or a write was rejected
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Diff lists the keys a create or update would change
+	// +optional
+	Diff []KeyDiff `json:"diff,omitempty"`
+}
+
+// KeyDiff describes the change of a single key
+type KeyDiff struct {
+	// Key is the changed key
+	Key string `json:"key"`
+
+	// Change is Added, Removed or Changed
+	Change string `json:"change"`
+
+	// Old is the previous value, shortened. Values of Secrets and binary data are not shown.
+	// +optional
+	Old string `json:"old,omitempty"`
+
+	// New is the new value, shortened. Values of Secrets and binary data are not shown.
+	// +optional
+	New string `json:"new,omitempty"`
+}
+
+// RevisionStatus describes a revision of the master content kept in the history
+type RevisionStatus struct {
+	// Revision is the number of the revision, it increases with every new master content
+	Revision int64 `json:"revision"`
+
+	// Hash is the content hash of the revision
+	Hash string `json:"hash"`
+
+	// Name is the name of the ControllerRevision holding the content
+	Name string `json:"name"`
+
+	// CreationTime is when the revision was first seen
+	CreationTime metav1.Time `json:"creationTime"`
+}
+
+// RolloutStatus reports the progress of a rollout
+type RolloutStatus struct {
+	// Revision is the content hash being rolled out
+	Revision string `json:"revision"`
+
+	// CurrentWave is the index of the wave being rolled out. It equals the
+	// number of waves once the rollout is complete.
+	CurrentWave int32 `json:"currentWave"`
+
+	// TotalWaves is the number of waves including the final wave of namespaces
+	// no wave selects
+	TotalWaves int32 `json:"totalWaves"`
+
+	// WaveHealthyTime is when the current wave became healthy, the pause is counted from it
+	// +optional
+	WaveHealthyTime *metav1.Time `json:"waveHealthyTime,omitempty"`
+
+	// Halted is set when a canary workload became unhealthy. The revision is
+	// not propagated any further, a new master content starts a new rollout.
+	// +optional
+	Halted bool `json:"halted,omitempty"`
+
+	// Message describes why the rollout was halted
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SourceStatus reports the revision of an external source
+type SourceStatus struct {
+	// Revision identifies the synced content, the commit for Git sources, the
+	// ETag or a hash of the document for HTTP sources and the resolved manifest
+	// digest for OCI sources
+	Revision string `json:"revision"`
+
+	// LastFetchTime is when the source was last fetched
+	// +optional
+	LastFetchTime *metav1.Time `json:"lastFetchTime,omitempty"`
+}
+
+// ClusterStatus reports the outcome of the last sync to a remote cluster
+type ClusterStatus struct {
+	// Name of the cluster as given in spec.clusters
+	Name string `json:"name"`
+
+	// Connected reports whether the API server of the cluster could be reached
+	Connected bool `json:"connected"`
+
+	// Message describes why the cluster could not be synced
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// TargetsTotal is the number of target ConfigMaps in the cluster
+	// +optional
+	TargetsTotal int32 `json:"targetsTotal"`
+
+	// TargetsSynced is the number of target ConfigMaps in the cluster that are in sync
+	// +optional
+	TargetsSynced int32 `json:"targetsSynced"`
+
+	// TargetsFailed is the number of target ConfigMaps in the cluster that could not be synced
+	// +optional
+	TargetsFailed int32 `json:"targetsFailed"`
 }
 
 // +kubebuilder:object:root=true
@@ -107,6 +750,10 @@ type ConfigMapSyncerStatus struct {
 // ConfigMapSyncer is the Schema for the configmapsyncers API.
 // +kubebuilder:resource:scope=Namespaced,shortName=cms
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Targets",type="integer",JSONPath=".status.targetsTotal"
+// +kubebuilder:printcolumn:name="Synced",type="integer",JSONPath=".status.targetsSynced"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.targetsFailed"
+// +kubebuilder:printcolumn:name="Master Version",type="string",JSONPath=".status.masterResourceVersion",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type ConfigMapSyncer struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigMapSyncReportSpec identifies the syncer and page a report belongs to.
+type ConfigMapSyncReportSpec struct {
+	// SyncerKind is the kind of the syncer this report belongs to. Reports of a
+	// ClusterConfigMapSyncer are stored in the namespace of its master ConfigMap.
+	// +optional
+	// +kubebuilder:validation:Enum=ConfigMapSyncer;ClusterConfigMapSyncer
+	// +kubebuilder:default=ConfigMapSyncer
+	SyncerKind string `json:"syncerKind,omitempty"`
+
+	// SyncerName is the name of the syncer this report belongs to
+	// +kubebuilder:validation:Required
+	SyncerName string `json:"syncerName"`
+
+	// Page is the zero-based index of this report among the reports of the syncer
+	// +kubebuilder:validation:Minimum=0
+	Page int32 `json:"page"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConfigMapSyncReport holds one page of the per-target sync statuses of a ConfigMapSyncer
+// or ClusterConfigMapSyncer whose targets do not fit in its own status.
+// +kubebuilder:resource:scope=Namespaced,shortName=cmsr
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.syncerKind"
+// +kubebuilder:printcolumn:name="Syncer",type="string",JSONPath=".spec.syncerName"
+// +kubebuilder:printcolumn:name="Page",type="integer",JSONPath=".spec.page"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ConfigMapSyncReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ConfigMapSyncReportSpec `json:"spec,omitempty"`
+
+	// SyncStatuses contains the sync status for the target ConfigMaps of this page
+	// +optional
+	SyncStatuses []SyncStatus `json:"syncStatuses,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConfigMapSyncReportList contains a list of ConfigMapSyncReport.
+type ConfigMapSyncReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConfigMapSyncReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ConfigMapSyncReport{}, &ConfigMapSyncReportList{})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RequesterAnnotation records the user who created the syncer or last changed its
+// spec. It is set by the mutating webhook and holds the JSON encoded UserInfo.
+const RequesterAnnotation = "conf-sync.com/requester"
+
+// Syncer is implemented by ConfigMapSyncer and ClusterConfigMapSyncer, which
+// share their spec and status and are reconciled by the same logic.
+// +kubebuilder:object:generate=false
+type Syncer interface {
+	metav1.Object
+	runtime.Object
+
+	// GetSpec returns the spec of the syncer
+	GetSpec() *ConfigMapSyncerSpec
+
+	// GetStatus returns the status of the syncer
+	GetStatus() *ConfigMapSyncerStatus
+}
+
+// GetSpec returns the spec of the ConfigMapSyncer
+func (s *ConfigMapSyncer) GetSpec() *ConfigMapSyncerSpec {
+	return &s.Spec
+}
+
+// GetStatus returns the status of the ConfigMapSyncer
+func (s *ConfigMapSyncer) GetStatus() *ConfigMapSyncerStatus {
+	return &s.Status
+}
+
+// GetSpec returns the spec of the ClusterConfigMapSyncer
+func (s *ClusterConfigMapSyncer) GetSpec() *ConfigMapSyncerSpec {
+	return &s.Spec
+}
+
+// GetStatus returns the status of the ClusterConfigMapSyncer
+func (s *ClusterConfigMapSyncer) GetStatus() *ConfigMapSyncerStatus {
+	return &s.Status
+}
+
+var (
+	_ Syncer = &ConfigMapSyncer{}
+	_ Syncer = &ClusterConfigMapSyncer{}
+)
+
+// GetRequester returns the user recorded in the RequesterAnnotation, or nil if
+// the annotation is missing or malformed
+func GetRequester(obj metav1.Object) *authenticationv1.UserInfo {
+	value, ok := obj.GetAnnotations()[RequesterAnnotation]
+	if !ok {
+		return nil
+	}
+	requester := &authenticationv1.UserInfo{}
+	if err := json.Unmarshal([]byte(value), requester); err != nil || requester.Username == "" {
+		return nil
+	}
+	return requester
+}
@@ -30,6 +30,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -38,7 +39,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
 	"github.com/devShahriar/configmap-sync-controller/internal/controller"
+	webhooksyncv1alpha1 "github.com/devShahriar/configmap-sync-controller/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -63,6 +66,8 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
+	var configFile string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -81,6 +86,11 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the validating webhooks are served. Requires a serving certificate, see --webhook-cert-path.")
+	flag.StringVar(&configFile, "config", "",
+		"Path to a controller configuration file. Flags that are set explicitly take precedence over the file.")
+	configFlags := config.BindFlags(flag.CommandLine)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,6 +99,29 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	controllerConfig := config.Default()
+	if configFile != "" {
+		var err error
+		controllerConfig, err = config.Load(configFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load controller config", "config", configFile)
+			os.Exit(1)
+		}
+	}
+	controllerConfig = configFlags.Override(controllerConfig)
+	if err := controllerConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid controller config")
+		os.Exit(1)
+	}
+	switch {
+	case controllerConfig.InsecureSkipTenantAuthorization:
+		setupLog.Info("WARNING: tenant authorization is turned off, namespaced ConfigMapSyncers may write " +
+			"targets into every namespace")
+	case !enableWebhooks:
+		setupLog.Info("Without --enable-webhooks namespaced ConfigMapSyncers only write targets in namespaces " +
+			"that list the syncer's namespace in their accept-from annotation")
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -185,6 +218,11 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "b5ef18bd.conf-sync.com",
+		// Only ConfigMaps written by the controller are cached, masters and
+		// unlabeled targets are read through the manager's APIReader.
+		Cache: cache.Options{
+			ByObject: controller.CacheByObject(),
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -203,12 +241,34 @@ func main() {
 	}
 
 	if err = (&controller.ConfigMapSyncerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		APIReader:       mgr.GetAPIReader(),
+		Recorder:        mgr.GetEventRecorderFor("configmapsyncer-controller"),
+		Config:          controllerConfig,
+		TrustRequesters: enableWebhooks,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ConfigMapSyncer")
 		os.Exit(1)
 	}
+	if err = (&controller.ClusterConfigMapSyncerReconciler{
+		ConfigMapSyncerReconciler: controller.ConfigMapSyncerReconciler{
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			APIReader: mgr.GetAPIReader(),
+			Recorder:  mgr.GetEventRecorderFor("clusterconfigmapsyncer-controller"),
+			Config:    controllerConfig,
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterConfigMapSyncer")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		if err = webhooksyncv1alpha1.SetupConfigMapSyncerWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ConfigMapSyncer")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -0,0 +1,276 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusters builds and caches clients for remote clusters whose
+// kubeconfigs are stored in Secrets.
+package clusters
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// requestTimeout bounds every request to a remote cluster, so that an
+	// unreachable cluster does not block a reconcile
+	requestTimeout = 30 * time.Second
+
+	// rewatchInterval is the wait before a dropped watch is restarted
+	rewatchInterval = 10 * time.Second
+)
+
+// Cluster is a connection to a remote cluster
+type Cluster struct {
+	// Client reads and writes directly against the API server of the cluster, there is no cache
+	client.Client
+
+	// watcher is used for long running watches, which must not be cut off by the request timeout
+	watcher   client.WithWatch
+	discovery discovery.ServerVersionInterface
+
+	// hosts are the API servers and proxies of every cluster in the kubeconfig
+	hosts []string
+}
+
+// Hosts returns the hosts of the API servers and proxies the kubeconfig points at
+func (c *Cluster) Hosts() []string {
+	return c.hosts
+}
+
+// Ping checks that the API server of the cluster can be reached
+func (c *Cluster) Ping() error {
+	if _, err := c.discovery.ServerVersion(); err != nil {
+		return fmt.Errorf("failed to reach cluster: %w", err)
+	}
+	return nil
+}
+
+// WatchConfigMap calls notify whenever the ConfigMap changes, until ctx is
+// done. A dropped watch is restarted from the last seen revision.
+func (c *Cluster) WatchConfigMap(ctx context.Context, key types.NamespacedName, notify func()) {
+	resourceVersion := ""
+	for {
+		resourceVersion = c.watchConfigMap(ctx, key, resourceVersion, notify)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(rewatchInterval):
+		}
+	}
+}
+
+// watchConfigMap runs a single watch and returns the revision to resume from
+func (c *Cluster) watchConfigMap(
+	ctx context.Context,
+	key types.NamespacedName,
+	resourceVersion string,
+	notify func(),
+) string {
+	w, err := c.watcher.Watch(ctx, &corev1.ConfigMapList{}, client.InNamespace(key.Namespace),
+		client.MatchingFields{"metadata.name": key.Name},
+		&client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: resourceVersion}})
+	if err != nil {
+		return resourceVersion
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion
+			}
+			switch ev.Type {
+			case watch.Error:
+				// A revision that is too old is dropped, the next watch starts from the current state
+				if err := apierrors.FromObject(ev.Object); apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return ""
+				}
+				return resourceVersion
+			case watch.Added, watch.Modified, watch.Deleted:
+				if cm, ok := ev.Object.(*corev1.ConfigMap); ok {
+					resourceVersion = cm.ResourceVersion
+				}
+				notify()
+			}
+		}
+	}
+}
+
+// entry is a cached connection together with the Secret revision it was built from
+type entry struct {
+	resourceVersion string
+	cluster         *Cluster
+}
+
+// Registry hands out connections to remote clusters. A connection is built the
+// first time a kubeconfig is used and reused until the Secret holding it changes.
+// It is safe for concurrent use.
+type Registry struct {
+	scheme *runtime.Scheme
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// NewRegistry returns an empty Registry whose clients use the given scheme
+func NewRegistry(scheme *runtime.Scheme) *Registry {
+	return &Registry{
+		scheme:  scheme,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Get returns the connection for the kubeconfig stored under key in the Secret
+func (r *Registry) Get(secret *corev1.Secret, key string) (*Cluster, error) {
+	id := fmt.Sprintf("%s/%s", types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, key)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.entries[id]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.cluster, nil
+	}
+
+	kubeconfig, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", secret.Namespace, secret.Name, key)
+	}
+	cluster, err := r.build(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig in secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	r.entries[id] = &entry{resourceVersion: secret.ResourceVersion, cluster: cluster}
+	return cluster, nil
+}
+
+// Evict drops the connections built from the Secret, so that a deleted Secret
+// or syncer does not keep its clients around. The next Get builds them again.
+func (r *Registry) Evict(secret types.NamespacedName) {
+	prefix := secret.String() + "/"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id := range r.entries {
+		if strings.HasPrefix(id, prefix) {
+			delete(r.entries, id)
+		}
+	}
+}
+
+// build creates a connection from a kubeconfig
+func (r *Registry) build(kubeconfig []byte) (*Cluster, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateKubeconfig(config); err != nil {
+		return nil, err
+	}
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	restConfig.Timeout = requestTimeout
+
+	c, err := client.New(restConfig, client.Options{Scheme: r.scheme})
+	if err != nil {
+		return nil, err
+	}
+	watchConfig := rest.CopyConfig(restConfig)
+	watchConfig.Timeout = 0
+	watcher, err := client.NewWithWatch(watchConfig, client.Options{Scheme: r.scheme})
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := kubeconfigHosts(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Cluster{Client: c, watcher: watcher, discovery: discoveryClient, hosts: hosts}, nil
+}
+
+// kubeconfigHosts returns the hosts of the API servers and proxies of every
+// cluster in the kubeconfig, including ports
+func kubeconfigHosts(config *clientcmdapi.Config) ([]string, error) {
+	var hosts []string
+	for name, cluster := range config.Clusters {
+		for _, server := range []string{cluster.Server, cluster.ProxyURL} {
+			if server == "" {
+				continue
+			}
+			if !strings.Contains(server, "://") {
+				server = "https://" + server
+			}
+			u, err := url.Parse(server)
+			if err != nil || u.Host == "" {
+				return nil, fmt.Errorf("cluster %q has an invalid URL %q", name, server)
+			}
+			hosts = append(hosts, u.Host)
+		}
+	}
+	return hosts, nil
+}
+
+// validateKubeconfig rejects kubeconfigs that make the controller run commands
+// or read files from its own file system. Kubeconfigs are written by the users
+// of the Secrets, so only inline credentials and certificates are allowed.
+func validateKubeconfig(config *clientcmdapi.Config) error {
+	for name, authInfo := range config.AuthInfos {
+		switch {
+		case authInfo.Exec != nil:
+			return fmt.Errorf("user %q uses an exec plugin, only inline credentials are allowed", name)
+		case authInfo.AuthProvider != nil:
+			return fmt.Errorf("user %q uses an auth provider, only inline credentials are allowed", name)
+		case authInfo.TokenFile != "":
+			return fmt.Errorf("user %q reads its token from a file, use token instead", name)
+		case authInfo.ClientCertificate != "":
+			return fmt.Errorf("user %q reads its client certificate from a file, use client-certificate-data instead", name)
+		case authInfo.ClientKey != "":
+			return fmt.Errorf("user %q reads its client key from a file, use client-key-data instead", name)
+		}
+	}
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("cluster %q reads its certificate authority from a file, use certificate-authority-data instead", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// kubeconfig returns a kubeconfig for a cluster at localhost whose user is
+// given as YAML
+func kubeconfig(user string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+users:
+- name: remote
+  user:
+%s
+`, user))
+}
+
+var _ = Describe("Registry", func() {
+	var registry *Registry
+
+	BeforeEach(func() {
+		registry = NewRegistry(clientgoscheme.Scheme)
+	})
+
+	secret := func(kubeconfig []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "remote", ResourceVersion: "1"},
+			Data:       map[string][]byte{"kubeconfig": kubeconfig},
+		}
+	}
+
+	It("should build a connection from inline credentials and reuse it", func() {
+		s := secret(kubeconfig("    token: secret"))
+		cluster, err := registry.Get(s, "kubeconfig")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(registry.Get(s, "kubeconfig")).To(BeIdenticalTo(cluster))
+
+		By("building it again once the Secret is evicted")
+		registry.Evict(types.NamespacedName{Namespace: "default", Name: "remote"})
+		Expect(registry.Get(s, "kubeconfig")).NotTo(BeIdenticalTo(cluster))
+	})
+
+	It("should report the hosts of the API servers and proxies", func() {
+		config := strings.Replace(string(kubeconfig("    token: secret")),
+			"    server: https://127.0.0.1:6443\n",
+			"    server: https://127.0.0.1:6443\n    proxy-url: http://proxy.example.com:3128\n", 1)
+		cluster, err := registry.Get(secret([]byte(config)), "kubeconfig")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Hosts()).To(ConsistOf("127.0.0.1:6443", "proxy.example.com:3128"))
+	})
+
+	It("should refuse kubeconfigs that run commands or read local files", func() {
+		_, err := registry.Get(secret(kubeconfig(`    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: /bin/sh
+      args: ["-c", "id"]`)), "kubeconfig")
+		Expect(err).To(MatchError(ContainSubstring("exec plugin")))
+
+		_, err = registry.Get(secret(kubeconfig("    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token")), "kubeconfig")
+		Expect(err).To(MatchError(ContainSubstring("token from a file")))
+
+		_, err = registry.Get(secret(kubeconfig(`    client-certificate: /etc/tls/tls.crt
+    client-key: /etc/tls/tls.key`)), "kubeconfig")
+		Expect(err).To(MatchError(ContainSubstring("from a file")))
+	})
+})
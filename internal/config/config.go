@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config contains the controller-wide configuration that can be
+// supplied through a file and overridden with command line flags.
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ControllerConfig holds the tunables for the ConfigMapSyncer controller.
+type ControllerConfig struct {
+	// MaxConcurrentReconciles is the number of ConfigMapSyncers reconciled in parallel
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// BaseBackoff is the initial delay before retrying a failed reconcile
+	BaseBackoff metav1.Duration `json:"baseBackoff,omitempty"`
+
+	// MaxBackoff caps the exponential delay between retries of a failed reconcile
+	MaxBackoff metav1.Duration `json:"maxBackoff,omitempty"`
+
+	// SyncerQPS is the sustained rate of retries allowed for a single ConfigMapSyncer
+	SyncerQPS float64 `json:"syncerQPS,omitempty"`
+
+	// SyncerBurst is the number of retries a single ConfigMapSyncer may make in a burst
+	SyncerBurst int `json:"syncerBurst,omitempty"`
+
+	// InsecureSkipTenantAuthorization turns off the check that a namespaced
+	// ConfigMapSyncer may write a target in another namespace, because the target
+	// namespace accepts it or the user who last changed the syncer may write the
+	// target themselves. Anyone who can create a ConfigMapSyncer can then write
+	// ConfigMaps into every namespace.
+	InsecureSkipTenantAuthorization bool `json:"insecureSkipTenantAuthorization,omitempty"`
+
+	// AllowSecretMasters lets syncers read a Secret as their master and copy its
+	// data into targets, which may be ConfigMaps readable by more users
+	AllowSecretMasters bool `json:"allowSecretMasters,omitempty"`
+
+	// DryRun makes every syncer plan its changes without writing any target, as if spec.dryRun was set
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// SourceHosts are the hosts HTTP, OCI and Git sources of namespaced ConfigMapSyncers
+	// may be read from, "*.example.com" matches all subdomains. Without entries
+	// only ClusterConfigMapSyncers may use these sources, since the controller
+	// could otherwise be made to fetch endpoints that only it can reach.
+	SourceHosts []string `json:"sourceHosts,omitempty"`
+
+	// ClusterHosts are the hosts the API servers and proxies in the kubeconfigs of
+	// namespaced ConfigMapSyncers may point at, with the same syntax as SourceHosts.
+	// Without entries only ClusterConfigMapSyncers may use remote clusters, since
+	// the controller could otherwise be made to send requests to endpoints that
+	// only it can reach.
+	ClusterHosts []string `json:"clusterHosts,omitempty"`
+}
+
+// Default returns the configuration used when neither a file nor flags override it.
+func Default() ControllerConfig {
+	return ControllerConfig{
+		MaxConcurrentReconciles: 1,
+		BaseBackoff:             metav1.Duration{Duration: 5 * time.Millisecond},
+		MaxBackoff:              metav1.Duration{Duration: 1000 * time.Second},
+		SyncerQPS:               1,
+		SyncerBurst:             5,
+	}
+}
+
+// IsZero reports whether no setting is set, as for reconcilers that were built
+// without a configuration
+func (c ControllerConfig) IsZero() bool {
+	return len(c.SourceHosts) == 0 && len(c.ClusterHosts) == 0 &&
+		reflect.DeepEqual(c, ControllerConfig{SourceHosts: c.SourceHosts, ClusterHosts: c.ClusterHosts})
+}
+
+// Load reads the configuration file at path on top of the defaults.
+func Load(path string) (ControllerConfig, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that the configuration values are usable.
+func (c ControllerConfig) Validate() error {
+	if c.MaxConcurrentReconciles < 1 {
+		return fmt.Errorf("maxConcurrentReconciles must be at least 1, got %d", c.MaxConcurrentReconciles)
+	}
+	if c.BaseBackoff.Duration <= 0 {
+		return fmt.Errorf("baseBackoff must be positive, got %s", c.BaseBackoff.Duration)
+	}
+	if c.MaxBackoff.Duration < c.BaseBackoff.Duration {
+		return fmt.Errorf("maxBackoff %s must not be less than baseBackoff %s",
+			c.MaxBackoff.Duration, c.BaseBackoff.Duration)
+	}
+	if c.SyncerQPS <= 0 {
+		return fmt.Errorf("syncerQPS must be positive, got %v", c.SyncerQPS)
+	}
+	if c.SyncerBurst < 1 {
+		return fmt.Errorf("syncerBurst must be at least 1, got %d", c.SyncerBurst)
+	}
+	if err := validateHosts("sourceHosts", c.SourceHosts); err != nil {
+		return err
+	}
+	return validateHosts("clusterHosts", c.ClusterHosts)
+}
+
+// validateHosts checks that an allow-list only holds host names
+func validateHosts(name string, hosts []string) error {
+	for _, host := range hosts {
+		if host == "" || host == "*" || strings.Contains(host, "/") {
+			return fmt.Errorf("%s must list host names, got %q", name, host)
+		}
+	}
+	return nil
+}
+
+// AllowsSourceHost reports whether namespaced syncers may read sources from
+// the host, which may include a port. Entries without a port match any port.
+func (c ControllerConfig) AllowsSourceHost(host string) bool {
+	return allowsHost(c.SourceHosts, host)
+}
+
+// AllowsClusterHost reports whether the kubeconfigs of namespaced syncers may
+// point at the host, which may include a port
+func (c ControllerConfig) AllowsClusterHost(host string) bool {
+	return allowsHost(c.ClusterHosts, host)
+}
+
+// allowsHost reports whether the host matches an entry of the allow-list
+func allowsHost(allowList []string, host string) bool {
+	host = strings.ToLower(host)
+	hostname := host
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		hostname = name
+	}
+	for _, allowed := range allowList {
+		allowed = strings.ToLower(allowed)
+		switch {
+		case allowed == host || allowed == hostname:
+			return true
+		case strings.HasPrefix(allowed, "*.") && strings.HasSuffix(hostname, allowed[1:]):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+)
+
+var _ = Describe("ControllerConfig", func() {
+	// writeConfig writes a configuration file and returns its path
+	writeConfig := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+
+	Context("Load", func() {
+		It("should read the file on top of the defaults", func() {
+			cfg, err := config.Load(writeConfig("maxConcurrentReconciles: 4\nsyncerQPS: 0.5\n"))
+			Expect(err).NotTo(HaveOccurred())
+			expected := config.Default()
+			expected.MaxConcurrentReconciles = 4
+			expected.SyncerQPS = 0.5
+			Expect(cfg).To(Equal(expected))
+		})
+
+		It("should reject unknown keys", func() {
+			_, err := config.Load(writeConfig("maxConcurrentReconcile: 4\n"))
+			Expect(err).To(MatchError(ContainSubstring("maxConcurrentReconcile")))
+		})
+
+		It("should reject invalid values", func() {
+			_, err := config.Load(writeConfig("syncerBurst: 0\n"))
+			Expect(err).To(MatchError(ContainSubstring("syncerBurst must be at least 1")))
+		})
+
+		It("should fail for a missing file", func() {
+			_, err := config.Load(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	DescribeTable("Validate",
+		func(mutate func(*config.ControllerConfig), message string) {
+			cfg := config.Default()
+			mutate(&cfg)
+			if message == "" {
+				Expect(cfg.Validate()).To(Succeed())
+			} else {
+				Expect(cfg.Validate()).To(MatchError(ContainSubstring(message)))
+			}
+		},
+		Entry("defaults", func(*config.ControllerConfig) {}, ""),
+		Entry("no reconciles", func(c *config.ControllerConfig) { c.MaxConcurrentReconciles = 0 },
+			"maxConcurrentReconciles must be at least 1"),
+		Entry("zero base backoff", func(c *config.ControllerConfig) { c.BaseBackoff = metav1.Duration{} },
+			"baseBackoff must be positive"),
+		Entry("max backoff below base backoff", func(c *config.ControllerConfig) {
+			c.MaxBackoff = metav1.Duration{Duration: time.Millisecond}
+		}, "must not be less than baseBackoff"),
+		Entry("max backoff equal to base backoff", func(c *config.ControllerConfig) { c.MaxBackoff = c.BaseBackoff }, ""),
+		Entry("negative qps", func(c *config.ControllerConfig) { c.SyncerQPS = -1 }, "syncerQPS must be positive"),
+		Entry("no burst", func(c *config.ControllerConfig) { c.SyncerBurst = 0 }, "syncerBurst must be at least 1"),
+		Entry("wildcard source host", func(c *config.ControllerConfig) { c.SourceHosts = []string{"*"} },
+			"sourceHosts must list host names"),
+		Entry("source host with a path", func(c *config.ControllerConfig) { c.SourceHosts = []string{"example.com/x"} },
+			"sourceHosts must list host names"),
+		Entry("empty cluster host", func(c *config.ControllerConfig) { c.ClusterHosts = []string{""} },
+			"clusterHosts must list host names"),
+	)
+
+	Context("Flags", func() {
+		It("should only override the values of flags that were set", func() {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags := config.BindFlags(fs)
+			Expect(fs.Parse([]string{"--syncer-burst=10", "--source-hosts=a.example.com, *.example.org"})).To(Succeed())
+
+			cfg, err := config.Load(writeConfig("syncerBurst: 3\nsyncerQPS: 2\nsourceHosts: [c.example.com]\n"))
+			Expect(err).NotTo(HaveOccurred())
+			cfg = flags.Override(cfg)
+			Expect(cfg.SyncerBurst).To(Equal(10))
+			Expect(cfg.SyncerQPS).To(Equal(2.0))
+			Expect(cfg.SourceHosts).To(Equal([]string{"a.example.com", "*.example.org"}))
+			Expect(cfg.MaxConcurrentReconciles).To(Equal(config.Default().MaxConcurrentReconciles))
+		})
+
+		It("should override a file value with the default when the flag is set to it", func() {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags := config.BindFlags(fs)
+			Expect(fs.Parse([]string{"--max-concurrent-reconciles=1"})).To(Succeed())
+
+			cfg := config.Default()
+			cfg.MaxConcurrentReconciles = 8
+			Expect(flags.Override(cfg).MaxConcurrentReconciles).To(Equal(1))
+		})
+	})
+
+	It("should match source hosts with ports and wildcards", func() {
+		cfg := config.ControllerConfig{SourceHosts: []string{"git.example.com", "*.example.org", "registry.local:5000"}}
+		Expect(cfg.AllowsSourceHost("git.example.com:443")).To(BeTrue())
+		Expect(cfg.AllowsSourceHost("GIT.example.com")).To(BeTrue())
+		Expect(cfg.AllowsSourceHost("a.b.example.org")).To(BeTrue())
+		Expect(cfg.AllowsSourceHost("example.org")).To(BeFalse())
+		Expect(cfg.AllowsSourceHost("registry.local:5000")).To(BeTrue())
+		Expect(cfg.AllowsSourceHost("registry.local:5001")).To(BeFalse())
+		Expect(cfg.AllowsSourceHost("evil.com")).To(BeFalse())
+		Expect(cfg.AllowsClusterHost("git.example.com")).To(BeFalse())
+	})
+})
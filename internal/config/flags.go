@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// Flags are the command line flags of the settings. Flags that are set
+// explicitly take precedence over a configuration file.
+type Flags struct {
+	set          *flag.FlagSet
+	values       ControllerConfig
+	sourceHosts  string
+	clusterHosts string
+}
+
+// BindFlags registers a flag for every setting on the flag set, defaulting to Default
+func BindFlags(fs *flag.FlagSet) *Flags {
+	defaults := Default()
+	f := &Flags{set: fs, values: defaults}
+	fs.IntVar(&f.values.MaxConcurrentReconciles, "max-concurrent-reconciles", defaults.MaxConcurrentReconciles,
+		"The number of ConfigMapSyncers reconciled in parallel.")
+	fs.DurationVar(&f.values.BaseBackoff.Duration, "base-backoff", defaults.BaseBackoff.Duration,
+		"The initial delay before retrying a failed reconcile.")
+	fs.DurationVar(&f.values.MaxBackoff.Duration, "max-backoff", defaults.MaxBackoff.Duration,
+		"The maximum delay between retries of a failed reconcile.")
+	fs.Float64Var(&f.values.SyncerQPS, "syncer-qps", defaults.SyncerQPS,
+		"The sustained rate of retries allowed for a single ConfigMapSyncer.")
+	fs.IntVar(&f.values.SyncerBurst, "syncer-burst", defaults.SyncerBurst,
+		"The number of retries a single ConfigMapSyncer may make in a burst.")
+	fs.BoolVar(&f.values.InsecureSkipTenantAuthorization, "insecure-skip-tenant-authorization",
+		defaults.InsecureSkipTenantAuthorization,
+		"INSECURE: if set, namespaced ConfigMapSyncers write targets in every namespace, without checking that the "+
+			"target namespace accepts them or that the user who last changed the syncer may write them.")
+	fs.BoolVar(&f.values.AllowSecretMasters, "allow-secret-masters", defaults.AllowSecretMasters,
+		"If set, syncers may use a Secret as their master and copy its data into ConfigMap or Secret targets.")
+	fs.BoolVar(&f.values.DryRun, "dry-run", defaults.DryRun,
+		"If set, every syncer only reports the changes a sync would make in its status, no target is written.")
+	fs.StringVar(&f.sourceHosts, "source-hosts", strings.Join(defaults.SourceHosts, ","),
+		"Comma-separated hosts that HTTP, OCI and Git sources of namespaced ConfigMapSyncers may be read from, "+
+			"*.example.com matches all subdomains.")
+	fs.StringVar(&f.clusterHosts, "cluster-hosts", strings.Join(defaults.ClusterHosts, ","),
+		"Comma-separated hosts that the API servers and proxies in kubeconfigs of namespaced ConfigMapSyncers "+
+			"may point at, *.example.com matches all subdomains.")
+	return f
+}
+
+// Override returns the configuration with the values of the flags that were
+// set explicitly. It is called after the flag set was parsed.
+func (f *Flags) Override(cfg ControllerConfig) ControllerConfig {
+	f.set.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "max-concurrent-reconciles":
+			cfg.MaxConcurrentReconciles = f.values.MaxConcurrentReconciles
+		case "base-backoff":
+			cfg.BaseBackoff = f.values.BaseBackoff
+		case "max-backoff":
+			cfg.MaxBackoff = f.values.MaxBackoff
+		case "syncer-qps":
+			cfg.SyncerQPS = f.values.SyncerQPS
+		case "syncer-burst":
+			cfg.SyncerBurst = f.values.SyncerBurst
+		case "insecure-skip-tenant-authorization":
+			cfg.InsecureSkipTenantAuthorization = f.values.InsecureSkipTenantAuthorization
+		case "allow-secret-masters":
+			cfg.AllowSecretMasters = f.values.AllowSecretMasters
+		case "dry-run":
+			cfg.DryRun = f.values.DryRun
+		case "source-hosts":
+			cfg.SourceHosts = splitHosts(f.sourceHosts)
+		case "cluster-hosts":
+			cfg.ClusterHosts = splitHosts(f.clusterHosts)
+		}
+	})
+	return cfg
+}
+
+// splitHosts returns the hosts of a comma-separated list
+func splitHosts(list string) []string {
+	var hosts []string
+	for _, host := range strings.Split(list, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
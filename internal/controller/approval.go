@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// ApprovalAutomatic propagates every master content as soon as it is read
+	ApprovalAutomatic = "Automatic"
+
+	// ApprovalManual stages every new master content until it is approved
+	ApprovalManual = "Manual"
+
+	// ApprovedRevisionAnnotation approves the pending revision with the given content hash or a unique prefix of it
+	ApprovedRevisionAnnotation = "configmapsyncer.conf-sync.com/approved-revision"
+
+	// ConditionReasonWaitingForApproval is the reason while a master content waits for approval
+	ConditionReasonWaitingForApproval = "WaitingForApproval"
+
+	// EventReasonApprovalRequired is the event reason when a new master content waits for approval
+	EventReasonApprovalRequired = "ApprovalRequired"
+
+	// EventReasonRevisionApproved is the event reason when a pending master content was approved
+	EventReasonRevisionApproved = "RevisionApproved"
+
+	// minApprovalLength is the length of the shortest hash prefix approving a revision
+	minApprovalLength = 7
+)
+
+// approvedBy reports whether the field or the annotation approves the content with the given hash
+func approvedBy(configMapSyncer syncv1alpha1.Syncer, hash string) bool {
+	for _, approval := range []string{
+		configMapSyncer.GetSpec().ApprovedRevision,
+		configMapSyncer.GetAnnotations()[ApprovedRevisionAnnotation],
+	} {
+		if len(approval) >= minApprovalLength && strings.HasPrefix(hash, approval) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvedMaster returns the master to propagate under manual approval. A
+// master content that is neither current nor approved is recorded as the
+// pending revision and the content of the current revision is propagated
+// instead, or nothing before the first content was approved.
+func (r *ConfigMapSyncerReconciler) approvedMaster(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	masterConfigMap *corev1.ConfigMap,
+) (*corev1.ConfigMap, error) {
+	status := configMapSyncer.GetStatus()
+	hash := computeContentHash(masterConfigMap, configMapSyncer)
+	if configMapSyncer.GetSpec().Approval != ApprovalManual || hash == status.CurrentRevision {
+		status.PendingRevision = nil
+		return masterConfigMap, nil
+	}
+	if approvedBy(configMapSyncer, hash) {
+		log.FromContext(ctx).Info("Revision approved", "hash", hash)
+		r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonRevisionApproved,
+			"Revision %s was approved", shortRevision(hash))
+		status.PendingRevision = nil
+		return masterConfigMap, nil
+	}
+
+	var current *corev1.ConfigMap
+	for _, revision := range status.Revisions {
+		if status.CurrentRevision == "" || revision.Hash != status.CurrentRevision {
+			continue
+		}
+		content, err := r.revisionContent(ctx, configMapSyncer, revision.Name)
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			current = withContent(masterConfigMap, content)
+		}
+	}
+
+	if status.PendingRevision == nil || status.PendingRevision.Hash != hash {
+		log.FromContext(ctx).Info("Revision waiting for approval", "hash", hash)
+		r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonApprovalRequired,
+			"Revision %s is waiting for approval", shortRevision(hash))
+		status.PendingRevision = &syncv1alpha1.PendingRevision{
+			Hash:         hash,
+			DetectedTime: metav1.Time{Time: time.Now()},
+		}
+	}
+	status.PendingRevision.Diff = diffContent(current, masterConfigMap, showsValues(configMapSyncer))
+	return current, nil
+}
+
+// setApprovalConditions reports a revision waiting for approval in the Progressing condition
+func (r *ConfigMapSyncerReconciler) setApprovalConditions(configMapSyncer syncv1alpha1.Syncer) {
+	pending := configMapSyncer.GetStatus().PendingRevision
+	if pending == nil {
+		return
+	}
+	r.setCondition(configMapSyncer, metav1.Condition{
+		Type:   ConditionTypeProgressing,
+		Status: metav1.ConditionFalse,
+		Reason: ConditionReasonWaitingForApproval,
+		Message: "Revision " + pending.Hash + " is waiting for approval, set spec.approvedRevision or the " +
+			ApprovedRevisionAnnotation + " annotation to it",
+	})
+}
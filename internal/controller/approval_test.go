@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Manual approval", func() {
+	const (
+		resourceName    = "approval-syncer"
+		masterName      = "approval-config"
+		targetNamespace = "approval-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+	masterKey := types.NamespacedName{Name: masterName, Namespace: "default"}
+	targetKey := types.NamespacedName{Name: masterName, Namespace: targetNamespace}
+
+	BeforeEach(func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		createMaster(ctx, masterName, map[string]string{"color": "blue"})
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+		for _, key := range []types.NamespacedName{masterKey, targetKey} {
+			target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, target))).To(Succeed())
+		}
+	})
+
+	It("should only propagate approved revisions", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				Approval:         ApprovalManual,
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		approve := func(hash string) {
+			resource.Annotations = map[string]string{ApprovedRevisionAnnotation: hash[:12]}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(resource.Status.PendingRevision).NotTo(BeNil())
+		Expect(resource.Status.PendingRevision.Diff).To(ConsistOf(
+			syncv1alpha1.KeyDiff{Key: "color", Change: KeyAdded, New: "blue"},
+		))
+		err := k8sClient.Get(ctx, targetKey, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		approve(resource.Status.PendingRevision.Hash)
+		Expect(resource.Status.PendingRevision).To(BeNil())
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("color", "blue"))
+
+		master := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, masterKey, master)).To(Succeed())
+		master.Data["color"] = "green"
+		Expect(k8sClient.Update(ctx, master)).To(Succeed())
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(resource.Status.PendingRevision).NotTo(BeNil())
+		Expect(resource.Status.PendingRevision.Diff).To(ConsistOf(
+			syncv1alpha1.KeyDiff{Key: "color", Change: KeyChanged, Old: "blue", New: "green"},
+		))
+		Expect(k8sClient.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("color", "blue"))
+
+		approve(resource.Status.PendingRevision.Hash)
+		Expect(k8sClient.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("color", "green"))
+	})
+
+	It("should roll back to a pinned revision without approval", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				Approval:         ApprovalManual,
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		setColor := func(color string) {
+			master := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, masterKey, master)).To(Succeed())
+			master.Data["color"] = color
+			Expect(k8sClient.Update(ctx, master)).To(Succeed())
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+		update := func(change func()) {
+			change()
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+		targetColor := func() string {
+			target := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, targetKey, target)).To(Succeed())
+			return target.Data["color"]
+		}
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		reconcileOnce(ctx, controllerReconciler, resource)
+		blue := resource.Status.PendingRevision.Hash
+		update(func() { resource.Spec.ApprovedRevision = blue[:12] })
+		setColor("green")
+		update(func() { resource.Spec.ApprovedRevision = resource.Status.PendingRevision.Hash[:12] })
+		Expect(targetColor()).To(Equal("green"))
+
+		setColor("red")
+		Expect(resource.Status.PendingRevision).NotTo(BeNil())
+		red := resource.Status.PendingRevision.Hash
+
+		update(func() { resource.Spec.PinnedRevision = blue[:12] })
+		Expect(resource.Status.PendingRevision).To(BeNil())
+		Expect(resource.Status.CurrentRevision).To(Equal(blue))
+		Expect(targetColor()).To(Equal("blue"))
+
+		update(func() { resource.Spec.PinnedRevision = "" })
+		Expect(resource.Status.CurrentRevision).To(Equal(blue))
+		Expect(resource.Status.PendingRevision).NotTo(BeNil())
+		Expect(resource.Status.PendingRevision.Hash).To(Equal(red))
+		Expect(resource.Status.PendingRevision.Diff).To(ConsistOf(
+			syncv1alpha1.KeyDiff{Key: "color", Change: KeyChanged, Old: "blue", New: "red"},
+		))
+		Expect(targetColor()).To(Equal("blue"))
+
+		update(func() { resource.Spec.ApprovedRevision = red[:12] })
+		Expect(resource.Status.PendingRevision).To(BeNil())
+		Expect(targetColor()).To(Equal("red"))
+	})
+})
@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// AcceptFromAnnotation lets a namespace accept targets from ConfigMapSyncers in the
+	// listed namespaces. It holds a comma separated list of namespaces, "*" accepts all.
+	AcceptFromAnnotation = "conf-sync.com/accept-from"
+
+	// EventReasonTargetForbidden is the event reason when a target was not written
+	// because the ConfigMapSyncer is not allowed to write it
+	EventReasonTargetForbidden = "TargetForbidden"
+)
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// tenantAuthorizer decides whether a ConfigMapSyncer may write a target. A target is
+// allowed when its namespace accepts the syncer's namespace through the
+// AcceptFromAnnotation, or when a SubjectAccessReview shows that the user who last
+// changed the syncer may write the target themselves. Decisions are cached for
+// the duration of a single sync.
+type tenantAuthorizer struct {
+	r         *ConfigMapSyncerReconciler
+	namespace string
+	kind      string
+	requester *authenticationv1.UserInfo
+	accepted  map[string]bool
+	reviewed  map[string]bool
+}
+
+// newTenantAuthorizer returns the authorizer for a sync, or nil if every target
+// may be written. ClusterConfigMapSyncers are created by cluster admins and are
+// not checked, neither is anything when the check is turned off insecurely.
+func (r *ConfigMapSyncerReconciler) newTenantAuthorizer(configMapSyncer syncv1alpha1.Syncer) *tenantAuthorizer {
+	if r.Config.InsecureSkipTenantAuthorization || configMapSyncer.GetNamespace() == "" {
+		return nil
+	}
+
+	authorizer := &tenantAuthorizer{
+		r:         r,
+		namespace: configMapSyncer.GetNamespace(),
+		kind:      targetKind(configMapSyncer),
+		accepted:  make(map[string]bool),
+		reviewed:  make(map[string]bool),
+	}
+
+	// The requester annotation can be forged unless the webhook maintains it
+	if r.TrustRequesters {
+		authorizer.requester = syncv1alpha1.GetRequester(configMapSyncer)
+	}
+	return authorizer
+}
+
+// authorize returns an empty string if the target may be written with the given
+// verb, otherwise the reason it may not
+func (a *tenantAuthorizer) authorize(ctx context.Context, key types.NamespacedName, verb string) (string, error) {
+	if a == nil {
+		return "", nil
+	}
+	return a.authorizeResource(ctx, "", a.resource(), a.kind, key, verb)
+}
+
+// authorizeResource returns an empty string if the object of the given
+// resource may be changed with the given verb, otherwise the reason it may not
+func (a *tenantAuthorizer) authorizeResource(
+	ctx context.Context,
+	group, resource, kind string,
+	key types.NamespacedName,
+	verb string,
+) (string, error) {
+	if a == nil || key.Namespace == a.namespace {
+		return "", nil
+	}
+
+	accepted, err := a.acceptedBy(ctx, key.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if accepted {
+		return "", nil
+	}
+
+	if a.requester == nil {
+		return fmt.Sprintf("namespace %s does not accept ConfigMaps from namespace %s, add it to the %s annotation",
+			key.Namespace, a.namespace, AcceptFromAnnotation), nil
+	}
+
+	allowed, err := a.review(ctx, group, resource, key, verb)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return fmt.Sprintf("user %s may not %s %s %s and namespace %s does not accept ConfigMaps from namespace %s",
+			a.requester.Username, verb, kind, key, key.Namespace, a.namespace), nil
+	}
+	return "", nil
+}
+
+// acceptedBy reports whether the namespace accepts targets from the syncer's namespace
+func (a *tenantAuthorizer) acceptedBy(ctx context.Context, namespace string) (bool, error) {
+	if accepted, ok := a.accepted[namespace]; ok {
+		return accepted, nil
+	}
+
+	ns := namespaceMetadata()
+	if err := a.r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, newTransientError("get namespace", err)
+	}
+
+	accepted := false
+	for _, source := range strings.Split(ns.Annotations[AcceptFromAnnotation], ",") {
+		source = strings.TrimSpace(source)
+		if source == "*" || source == a.namespace {
+			accepted = true
+			break
+		}
+	}
+	a.accepted[namespace] = accepted
+	return accepted, nil
+}
+
+// resource returns the resource of the targets for access reviews
+func (a *tenantAuthorizer) resource() string {
+	if a.kind == KindSecret {
+		return "secrets"
+	}
+	return "configmaps"
+}
+
+// review asks the API server whether the requester may perform verb on the object
+func (a *tenantAuthorizer) review(
+	ctx context.Context,
+	group, resource string,
+	key types.NamespacedName,
+	verb string,
+) (bool, error) {
+	cacheKey := verb + "/" + resource + "." + group + "/" + key.String()
+	if allowed, ok := a.reviewed[cacheKey]; ok {
+		return allowed, nil
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(a.requester.Extra))
+	for name, value := range a.requester.Extra {
+		extra[name] = authorizationv1.ExtraValue(value)
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: key.Namespace,
+				Verb:      verb,
+				Group:     group,
+				Resource:  resource,
+				Name:      key.Name,
+			},
+			User:   a.requester.Username,
+			UID:    a.requester.UID,
+			Groups: a.requester.Groups,
+			Extra:  extra,
+		},
+	}
+	if err := a.r.Create(ctx, review); err != nil {
+		return false, newTransientError("create SubjectAccessReview", err)
+	}
+
+	a.reviewed[cacheKey] = review.Status.Allowed
+	return review.Status.Allowed, nil
+}
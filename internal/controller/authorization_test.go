@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+)
+
+var _ = Describe("Tenant authorization", func() {
+	const (
+		resourceName    = "tenant-syncer"
+		masterName      = "tenant-master"
+		targetNamespace = "tenant-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+	BeforeEach(func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		createMaster(ctx, masterName, map[string]string{"key": "value"})
+
+		requester, err := json.Marshal(authenticationv1.UserInfo{Username: "tenant", Groups: []string{"system:authenticated"}})
+		Expect(err).NotTo(HaveOccurred())
+		resource := &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        resourceName,
+				Namespace:   "default",
+				Annotations: map[string]string{syncv1alpha1.RequesterAnnotation: string(requester)},
+			},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ConfigMapSyncer{}, typeNamespacedName)
+	})
+
+	It("should only write targets the namespace accepts or the requester may write", func() {
+		controllerReconciler := &ConfigMapSyncerReconciler{
+			Client:          k8sClient,
+			Scheme:          k8sClient.Scheme(),
+			Config:          config.Default(),
+			TrustRequesters: true,
+		}
+
+		By("refusing the target when the requester has no access")
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		resource := &syncv1alpha1.ConfigMapSyncer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.SyncStatuses).To(ConsistOf(SatisfyAll(
+			HaveField("Namespace", targetNamespace),
+			HaveField("Status", SyncStatusForbidden),
+		)))
+		Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionTypeDegraded)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace},
+			&corev1.ConfigMap{})).NotTo(Succeed())
+
+		By("writing the target once the namespace accepts the syncer's namespace")
+		namespace := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: targetNamespace}, namespace)).To(Succeed())
+		namespace.Annotations = map[string]string{AcceptFromAnnotation: "team-a, default"}
+		Expect(k8sClient.Update(ctx, namespace)).To(Succeed())
+
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace},
+			&corev1.ConfigMap{})).To(Succeed())
+
+		By("reporting the target that is in sync as forbidden once the namespace revokes it")
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: targetNamespace}, namespace)).To(Succeed())
+		namespace.Annotations = nil
+		Expect(k8sClient.Update(ctx, namespace)).To(Succeed())
+
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.SyncStatuses).To(ConsistOf(HaveField("Status", SyncStatusForbidden)))
+	})
+
+	It("should check namespaced syncers unless authorization is skipped insecurely", func() {
+		namespaced := &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+		}
+		controllerReconciler := &ConfigMapSyncerReconciler{}
+		Expect(controllerReconciler.newTenantAuthorizer(namespaced)).NotTo(BeNil())
+		Expect(controllerReconciler.newTenantAuthorizer(&syncv1alpha1.ClusterConfigMapSyncer{})).To(BeNil())
+
+		controllerReconciler.Config = config.Default()
+		controllerReconciler.Config.InsecureSkipTenantAuthorization = true
+		Expect(controllerReconciler.newTenantAuthorizer(namespaced)).To(BeNil())
+	})
+})
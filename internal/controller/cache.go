@@ -0,0 +1,67 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CacheByObject restricts the manager's ConfigMap and Secret informers to
+// targets written by the controller. Caching every ConfigMap in the cluster
+// costs a lot of memory on big clusters, so masters, credentials and targets
+// that have not been labeled yet are read through the uncached APIReader instead.
+func CacheByObject() map[client.Object]cache.ByObject {
+	managed, err := labels.NewRequirement(SourceConfigMapLabel, selection.Exists, nil)
+	if err != nil {
+		// The requirement is built from constants and can only fail on a programming error
+		panic(err)
+	}
+
+	return map[client.Object]cache.ByObject{
+		&corev1.ConfigMap{}: {Label: labels.NewSelector().Add(*managed)},
+		&corev1.Secret{}:    {Label: labels.NewSelector().Add(*managed)},
+	}
+}
+
+// namespaceMetadata returns an empty Namespace that is read as metadata only.
+// Only the labels and annotations of namespaces are needed, so the cache keeps
+// a metadata informer instead of full Namespace objects.
+func namespaceMetadata() *metav1.PartialObjectMetadata {
+	ns := &metav1.PartialObjectMetadata{}
+	ns.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
+	return ns
+}
+
+// namespaceMetadataList returns an empty list of Namespaces read as metadata only
+func namespaceMetadataList() *metav1.PartialObjectMetadataList {
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NamespaceList"))
+	return list
+}
+
+// apiReader returns the reader for objects outside the label-filtered cache
+func (r *ConfigMapSyncerReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
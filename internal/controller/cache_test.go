@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// BenchmarkInformerMemory measures the heap held by the ConfigMap and Secret
+// informers on a cluster with many objects the controller does not manage,
+// with and without the label selector of CacheByObject. Run it with
+//
+//	go test ./internal/controller -run '^$' -bench InformerMemory -benchtime 3x
+func BenchmarkInformerMemory(b *testing.B) {
+	const unrelated, managed = 5000, 50
+	value := strings.Repeat("x", 1024)
+
+	var selector string
+	for _, byObject := range CacheByObject() {
+		selector = byObject.Label.String()
+	}
+	objects := make([]apiruntime.Object, 0, 2*(unrelated+managed))
+	for i := range unrelated + managed {
+		meta := metav1.ObjectMeta{Name: fmt.Sprintf("object-%d", i), Namespace: fmt.Sprintf("namespace-%d", i%100)}
+		if i < managed {
+			meta.Labels = map[string]string{SourceConfigMapLabel: "default.master"}
+		}
+		objects = append(objects,
+			&corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"value": value}},
+			&corev1.Secret{ObjectMeta: meta, Data: map[string][]byte{"value": []byte(value)}},
+		)
+	}
+
+	for _, filtered := range []bool{false, true} {
+		b.Run(fmt.Sprintf("filtered=%t", filtered), func(b *testing.B) {
+			clientset := fake.NewClientset(objects...)
+			for range b.N {
+				before := heapInUse()
+				factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+					informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+						if filtered {
+							options.LabelSelector = selector
+						}
+					}))
+				configMaps := factory.Core().V1().ConfigMaps().Informer()
+				secrets := factory.Core().V1().Secrets().Informer()
+				stop := make(chan struct{})
+				factory.Start(stop)
+				factory.WaitForCacheSync(stop)
+				cached := len(configMaps.GetStore().List()) + len(secrets.GetStore().List())
+				b.ReportMetric(float64(heapInUse()-before)/(1<<20), "MiB")
+				b.ReportMetric(float64(cached), "objects")
+				close(stop)
+				factory.Shutdown()
+			}
+		})
+	}
+}
+
+// heapInUse returns the bytes of live heap objects after a garbage collection
+func heapInUse() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/clusters"
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+)
+
+const (
+	// KindConfigMapSyncer is the kind of the namespaced syncer
+	KindConfigMapSyncer = "ConfigMapSyncer"
+
+	// KindClusterConfigMapSyncer is the kind of the cluster-scoped syncer
+	KindClusterConfigMapSyncer = "ClusterConfigMapSyncer"
+)
+
+// ClusterConfigMapSyncerReconciler reconciles a ClusterConfigMapSyncer object.
+// It shares the sync logic and settings of the ConfigMapSyncerReconciler.
+type ClusterConfigMapSyncerReconciler struct {
+	ConfigMapSyncerReconciler
+}
+
+// +kubebuilder:rbac:groups=sync.conf-sync.com,resources=clusterconfigmapsyncers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sync.conf-sync.com,resources=clusterconfigmapsyncers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sync.conf-sync.com,resources=clusterconfigmapsyncers/finalizers,verbs=update
+
+// Reconcile syncs the master ConfigMap of a ClusterConfigMapSyncer to its targets
+func (r *ClusterConfigMapSyncerReconciler) Reconcile(
+	ctx context.Context,
+	req ctrl.Request,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling ClusterConfigMapSyncer", "name", req.Name)
+
+	clusterSyncer := &syncv1alpha1.ClusterConfigMapSyncer{}
+	if err := r.Get(ctx, req.NamespacedName, clusterSyncer); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("ClusterConfigMapSyncer resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get ClusterConfigMapSyncer")
+		return ctrl.Result{}, newTransientError("get ClusterConfigMapSyncer", err)
+	}
+
+	return r.reconcileSyncer(ctx, clusterSyncer)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterConfigMapSyncerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	cfg := r.Config
+	if cfg.IsZero() {
+		cfg = config.Default()
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	r.Config = cfg
+	if r.Clusters == nil {
+		r.Clusters = clusters.NewRegistry(mgr.GetScheme())
+	}
+	r.remoteMasters = newRemoteMasterWatches()
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&syncv1alpha1.ClusterConfigMapSyncer{}).
+		Owns(&corev1.ConfigMap{}).
+		WatchesRawSource(r.remoteMasters.source()).
+		Named("clusterconfigmapsyncer").
+		WithOptions(crcontroller.Options{
+			MaxConcurrentReconciles: cfg.MaxConcurrentReconciles,
+			RateLimiter:             newRateLimiter(cfg),
+		}).
+		Complete(r)
+}
+
+// kindOf returns the kind of a syncer. Only ClusterConfigMapSyncers are cluster-scoped.
+func kindOf(configMapSyncer syncv1alpha1.Syncer) string {
+	if configMapSyncer.GetNamespace() == "" {
+		return KindClusterConfigMapSyncer
+	}
+	return KindConfigMapSyncer
+}
+
+// validateMasterNamespace rejects namespaced ConfigMapSyncers whose master
+// ConfigMap lives in another namespace. Reading masters from other namespaces
+// would let namespace tenants copy data they cannot read themselves, so that
+// is reserved for ClusterConfigMapSyncers. Masters in remote clusters are read
+// with the credentials of the tenant's kubeconfig and may live anywhere.
+func validateMasterNamespace(configMapSyncer syncv1alpha1.Syncer) error {
+	namespace := configMapSyncer.GetNamespace()
+	if namespace == "" || configMapSyncer.GetSpec().MasterConfigMap.KubeconfigSecretRef != nil {
+		return nil
+	}
+	if master := configMapSyncer.GetSpec().MasterConfigMap.Namespace; master != namespace {
+		return newValidationError("spec.masterConfigMap.namespace", fmt.Errorf(
+			"a ConfigMapSyncer may only use a master ConfigMap from its own namespace %q, not %q; use a ClusterConfigMapSyncer instead",
+			namespace, master))
+	}
+	return nil
+}
+
+// validateMasterKind rejects Secret masters unless the controller allows them.
+// Targets are often readable by more users than the master, so copying Secret
+// data into them is an admin decision.
+func (r *ConfigMapSyncerReconciler) validateMasterKind(configMapSyncer syncv1alpha1.Syncer) error {
+	spec := configMapSyncer.GetSpec()
+	if spec.Source != nil || spec.MasterConfigMap.Kind != KindSecret || r.Config.AllowSecretMasters {
+		return nil
+	}
+	return newValidationError("spec.masterConfigMap.kind", fmt.Errorf(
+		"secret masters are not allowed, the controller must be started with --allow-secret-masters"))
+}
+
+// validateScope rejects specs that read master ConfigMaps, sources or
+// kubeconfig Secrets the syncer is not allowed to use
+func (r *ConfigMapSyncerReconciler) validateScope(configMapSyncer syncv1alpha1.Syncer) error {
+	if err := validateMasterNamespace(configMapSyncer); err != nil {
+		return err
+	}
+	if err := r.validateMasterKind(configMapSyncer); err != nil {
+		return err
+	}
+	if err := r.validateSource(configMapSyncer); err != nil {
+		return err
+	}
+	return validateClusters(configMapSyncer)
+}
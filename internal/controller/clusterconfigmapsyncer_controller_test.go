@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("ClusterConfigMapSyncer Controller", func() {
+	const (
+		resourceName    = "cluster-syncer"
+		masterName      = "cluster-master"
+		masterNamespace = "kube-system"
+		targetNamespace = "kube-public"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+
+	BeforeEach(func() {
+		master := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: masterNamespace},
+			Data:       map[string]string{"key": "value"},
+		}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, master))).To(Succeed())
+
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: masterNamespace},
+				TargetNamespaces: []string{targetNamespace},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+
+		target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: targetNamespace}}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, target))).To(Succeed())
+	})
+
+	It("should sync a master from another namespace", func() {
+		controllerReconciler := &ClusterConfigMapSyncerReconciler{
+			ConfigMapSyncerReconciler: ConfigMapSyncerReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			},
+		}
+
+		By("adding the finalizer on the first pass")
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("syncing the master on the second pass")
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("key", "value"))
+
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Syncer scope", func() {
+	It("should only allow namespaced syncers to use masters from their own namespace", func() {
+		namespaced := &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: "syncer", Namespace: "team-a"},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap: syncv1alpha1.ConfigMapReference{Name: "app-config", Namespace: "team-a"},
+			},
+		}
+		Expect(validateMasterNamespace(namespaced)).To(Succeed())
+
+		namespaced.Spec.MasterConfigMap.Namespace = "kube-system"
+		err := validateMasterNamespace(namespaced)
+		Expect(IsValidationError(err)).To(BeTrue())
+
+		cluster := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: "syncer"},
+			Spec:       namespaced.Spec,
+		}
+		Expect(validateMasterNamespace(cluster)).To(Succeed())
+	})
+
+	It("should store reports of cluster syncers next to the master", func() {
+		cluster := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: "syncer"},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap: syncv1alpha1.ConfigMapReference{Name: "app-config", Namespace: "platform"},
+			},
+		}
+		Expect(kindOf(cluster)).To(Equal(KindClusterConfigMapSyncer))
+		Expect(reportNamespace(cluster)).To(Equal("platform"))
+		Expect(reportName(cluster, 0)).To(Equal("cluster-syncer-report-0"))
+	})
+})
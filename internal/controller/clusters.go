@@ -0,0 +1,248 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/clusters"
+)
+
+// defaultKubeconfigKey is the Secret key holding the kubeconfig when none is given
+const defaultKubeconfigKey = "kubeconfig"
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// targetCluster is a cluster targets are written to
+type targetCluster struct {
+	// name is the name from spec.clusters, empty for the local cluster
+	name string
+
+	// client writes targets. For the local cluster it reads from the cache.
+	client client.Client
+
+	// reader reads directly from the API server
+	reader client.Reader
+
+	// cached is set when client only sees labeled ConfigMaps
+	cached bool
+
+	// dryRun is set when client sends its writes as server-side dry runs
+	dryRun bool
+}
+
+// isLocal reports whether the cluster is the one the controller runs in
+func (c *targetCluster) isLocal() bool {
+	return c.name == ""
+}
+
+// displayName returns the name used for the cluster in logs and messages
+func (c *targetCluster) displayName() string {
+	if c.isLocal() {
+		return "local"
+	}
+	return c.name
+}
+
+// namespaceKey identifies a namespace across clusters
+func namespaceKey(cluster, namespace string) string {
+	if cluster == "" {
+		return namespace
+	}
+	return cluster + "/" + namespace
+}
+
+// localCluster returns the cluster the controller runs in
+func (r *ConfigMapSyncerReconciler) localCluster() *targetCluster {
+	return &targetCluster{client: r.Client, reader: r.apiReader(), cached: true}
+}
+
+// clusterRegistry returns the registry of remote cluster connections
+func (r *ConfigMapSyncerReconciler) clusterRegistry() *clusters.Registry {
+	if r.Clusters == nil {
+		r.Clusters = clusters.NewRegistry(r.Scheme)
+	}
+	return r.Clusters
+}
+
+// secretKey returns the key of a Secret referenced by the syncer. The
+// namespace defaults to the one of the syncer.
+func secretKey(configMapSyncer syncv1alpha1.Syncer, name, namespace string) types.NamespacedName {
+	if namespace == "" {
+		namespace = configMapSyncer.GetNamespace()
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
+
+// kubeconfigSecretKey returns the Secret holding the kubeconfig of a cluster
+func kubeconfigSecretKey(configMapSyncer syncv1alpha1.Syncer, ref syncv1alpha1.SecretKeyReference) types.NamespacedName {
+	return secretKey(configMapSyncer, ref.Name, ref.Namespace)
+}
+
+// evictClusters drops the connections built from the kubeconfig Secrets of a
+// syncer that is deleted
+func (r *ConfigMapSyncerReconciler) evictClusters(configMapSyncer syncv1alpha1.Syncer) {
+	spec := configMapSyncer.GetSpec()
+	if ref := spec.MasterConfigMap.KubeconfigSecretRef; ref != nil {
+		r.clusterRegistry().Evict(kubeconfigSecretKey(configMapSyncer, *ref))
+	}
+	for _, cluster := range spec.Clusters {
+		r.clusterRegistry().Evict(kubeconfigSecretKey(configMapSyncer, cluster.KubeconfigSecretRef))
+	}
+}
+
+// validateClusters rejects kubeconfig Secrets a syncer may not read
+func validateClusters(configMapSyncer syncv1alpha1.Syncer) error {
+	spec := configMapSyncer.GetSpec()
+	if ref := spec.MasterConfigMap.KubeconfigSecretRef; ref != nil {
+		if err := validateSecretNamespace(configMapSyncer, "spec.masterConfigMap.kubeconfigSecretRef.namespace", ref.Namespace); err != nil {
+			return err
+		}
+	}
+	for i, cluster := range spec.Clusters {
+		field := fmt.Sprintf("spec.clusters[%d].kubeconfigSecretRef.namespace", i)
+		if err := validateSecretNamespace(configMapSyncer, field, cluster.KubeconfigSecretRef.Namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSecretNamespace rejects a Secret the syncer may not read. A
+// namespaced ConfigMapSyncer may only use Secrets from its own namespace, a
+// ClusterConfigMapSyncer has to name the namespace.
+func validateSecretNamespace(configMapSyncer syncv1alpha1.Syncer, field, secretNamespace string) error {
+	namespace := configMapSyncer.GetNamespace()
+	switch {
+	case namespace == "" && secretNamespace == "":
+		return newValidationError(field, fmt.Errorf("a ClusterConfigMapSyncer has to name the namespace of the Secret"))
+	case namespace != "" && secretNamespace != "" && secretNamespace != namespace:
+		return newValidationError(field, fmt.Errorf(
+			"a ConfigMapSyncer may only use Secrets from its own namespace %q, not %q",
+			namespace, secretNamespace))
+	}
+	return nil
+}
+
+// connect returns a healthy connection to the cluster whose kubeconfig is
+// stored in the referenced Secret
+func (r *ConfigMapSyncerReconciler) connect(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	ref syncv1alpha1.SecretKeyReference,
+) (*clusters.Cluster, error) {
+	secretKey := kubeconfigSecretKey(configMapSyncer, ref)
+	secret := &corev1.Secret{}
+	if err := r.apiReader().Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.clusterRegistry().Evict(secretKey)
+		}
+		return nil, fmt.Errorf("failed to get kubeconfig Secret %s: %w", secretKey, err)
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = defaultKubeconfigKey
+	}
+	remote, err := r.clusterRegistry().Get(secret, key)
+	if err != nil {
+		return nil, err
+	}
+	// Kubeconfigs of namespaced syncers may only point at allowed hosts, the
+	// controller would otherwise send its requests wherever the tenant likes
+	if configMapSyncer.GetNamespace() != "" {
+		for _, host := range remote.Hosts() {
+			if !r.Config.AllowsClusterHost(host) {
+				return nil, fmt.Errorf(
+					"a ConfigMapSyncer may only connect to the hosts allowed with --cluster-hosts, not %q", host)
+			}
+		}
+	}
+	if err := remote.Ping(); err != nil {
+		return nil, err
+	}
+	return remote, nil
+}
+
+// syncRemoteClusters syncs the master ConfigMap to every cluster in
+// spec.clusters, merges the outcome into the result and records the health of
+// each cluster in the status. It returns the clusters that could be reached.
+func (r *ConfigMapSyncerReconciler) syncRemoteClusters(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	masterConfigMap *corev1.ConfigMap,
+	result *syncResult,
+) map[string]*targetCluster {
+	logger := log.FromContext(ctx)
+	connected := map[string]*targetCluster{}
+
+	var statuses []syncv1alpha1.ClusterStatus
+	for _, ref := range configMapSyncer.GetSpec().Clusters {
+		clusterStatus := syncv1alpha1.ClusterStatus{Name: ref.Name}
+
+		remote, err := r.connect(ctx, configMapSyncer, ref.KubeconfigSecretRef)
+		if err != nil {
+			logger.Error(err, "Failed to connect to cluster", "cluster", ref.Name)
+			clusterStatus.Message = err.Error()
+			result.incompleteClusters[ref.Name] = true
+			result.statuses = append(result.statuses, syncv1alpha1.SyncStatus{
+				Cluster: ref.Name,
+				Status:  SyncStatusFailed,
+				Message: fmt.Sprintf("Failed to connect to cluster: %v", err),
+			})
+			statuses = append(statuses, clusterStatus)
+			continue
+		}
+		clusterStatus.Connected = true
+		cluster := &targetCluster{name: ref.Name, client: remote, reader: remote}
+		if r.dryRun(configMapSyncer) {
+			cluster = cluster.asDryRun()
+		}
+		connected[ref.Name] = cluster
+
+		clusterResult, err := r.syncConfigMaps(ctx, configMapSyncer, masterConfigMap, cluster)
+		if err != nil {
+			logger.Error(err, "Failed to sync ConfigMaps", "cluster", ref.Name)
+			clusterStatus.Message = fmt.Sprintf("Failed to sync ConfigMaps: %v", err)
+			result.incompleteClusters[ref.Name] = true
+			result.statuses = append(result.statuses, syncv1alpha1.SyncStatus{
+				Cluster: ref.Name,
+				Status:  SyncStatusFailed,
+				Message: clusterStatus.Message,
+			})
+			statuses = append(statuses, clusterStatus)
+			continue
+		}
+
+		clusterStatus.TargetsTotal = int32(clusterResult.total())
+		clusterStatus.TargetsFailed = int32(clusterResult.failed())
+		clusterStatus.TargetsSynced = clusterStatus.TargetsTotal - clusterStatus.TargetsFailed - int32(clusterResult.pending())
+		result.merge(clusterResult)
+		statuses = append(statuses, clusterStatus)
+	}
+
+	configMapSyncer.GetStatus().Clusters = statuses
+	return connected
+}
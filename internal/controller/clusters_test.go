@@ -0,0 +1,224 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/clusters"
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+)
+
+var _ = Describe("Multi-cluster propagation", Ordered, func() {
+	const (
+		resourceName    = "fleet-syncer"
+		masterName      = "fleet-master"
+		secretName      = "edge-kubeconfig"
+		targetNamespace = "fleet-target"
+	)
+
+	var (
+		remoteEnv    *envtest.Environment
+		remoteClient client.Client
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+	BeforeAll(func() {
+		By("starting a second API server as the remote cluster")
+		remoteEnv = &envtest.Environment{BinaryAssetsDirectory: testEnv.BinaryAssetsDirectory}
+		remoteCfg, err := remoteEnv.Start()
+		Expect(err).NotTo(HaveOccurred())
+		remoteClient, err = client.New(remoteCfg, client.Options{Scheme: k8sClient.Scheme()})
+		Expect(err).NotTo(HaveOccurred())
+
+		admin, err := remoteEnv.AddUser(envtest.User{Name: "fleet-admin", Groups: []string{"system:masters"}}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		kubeconfig, err := admin.KubeConfig()
+		Expect(err).NotTo(HaveOccurred())
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"},
+			Data:       map[string][]byte{"kubeconfig": kubeconfig},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+		for _, c := range []client.Client{k8sClient, remoteClient} {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        targetNamespace,
+				Annotations: map[string]string{AcceptFromAnnotation: "default"},
+			}}
+			Expect(client.IgnoreAlreadyExists(c.Create(ctx, namespace))).To(Succeed())
+		}
+		createMaster(ctx, masterName, map[string]string{"key": "value"})
+	})
+
+	AfterAll(func() {
+		Expect(k8sClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}})).To(Succeed())
+		Expect(remoteEnv.Stop()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ConfigMapSyncer{}, typeNamespacedName)
+	})
+
+	newReconciler := func() *ConfigMapSyncerReconciler {
+		cfg := config.Default()
+		cfg.ClusterHosts = []string{"127.0.0.1"}
+		return &ConfigMapSyncerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   cfg,
+			Clusters: clusters.NewRegistry(k8sClient.Scheme()),
+		}
+	}
+
+	createSyncer := func(secret string) {
+		resource := &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				Clusters: []syncv1alpha1.ClusterReference{{
+					Name:                "edge",
+					KubeconfigSecretRef: syncv1alpha1.SecretKeyReference{Name: secret, Key: "kubeconfig"},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+	}
+
+	It("should sync the master into the local and the remote cluster", func() {
+		createSyncer(secretName)
+		controllerReconciler := newReconciler()
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		targetKey := types.NamespacedName{Name: masterName, Namespace: targetNamespace}
+		for _, c := range []client.Client{k8sClient, remoteClient} {
+			target := &corev1.ConfigMap{}
+			Expect(c.Get(ctx, targetKey, target)).To(Succeed())
+			Expect(target.Data).To(Equal(map[string]string{"key": "value"}))
+		}
+
+		resource := &syncv1alpha1.ConfigMapSyncer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.TargetsTotal).To(Equal(int32(2)))
+		Expect(resource.Status.TargetsSynced).To(Equal(int32(2)))
+		Expect(resource.Status.SyncStatuses).To(ConsistOf(
+			HaveField("Cluster", ""),
+			HaveField("Cluster", "edge"),
+		))
+		Expect(resource.Status.Clusters).To(ConsistOf(SatisfyAll(
+			HaveField("Name", "edge"),
+			HaveField("Connected", true),
+			HaveField("TargetsSynced", int32(1)),
+		)))
+	})
+
+	It("should report a cluster it cannot connect to", func() {
+		createSyncer("missing-kubeconfig")
+		controllerReconciler := newReconciler()
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		resource := &syncv1alpha1.ConfigMapSyncer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.Clusters).To(ConsistOf(SatisfyAll(
+			HaveField("Name", "edge"),
+			HaveField("Connected", false),
+			HaveField("Message", ContainSubstring("missing-kubeconfig")),
+		)))
+		Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionTypeDegraded)).To(BeTrue())
+	})
+
+	It("should refuse API servers that are not allowed for namespaced syncers", func() {
+		createSyncer(secretName)
+		controllerReconciler := newReconciler()
+		controllerReconciler.Config.ClusterHosts = []string{"*.example.com"}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		resource := &syncv1alpha1.ConfigMapSyncer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.Clusters).To(ConsistOf(SatisfyAll(
+			HaveField("Name", "edge"),
+			HaveField("Connected", false),
+			HaveField("Message", ContainSubstring("--cluster-hosts")),
+		)))
+	})
+
+	It("should pull the master from the remote cluster", func() {
+		remoteMaster := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "central-config", Namespace: "default"},
+			Data:       map[string]string{"source": "central"},
+		}
+		Expect(remoteClient.Create(ctx, remoteMaster)).To(Succeed())
+
+		resource := &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap: syncv1alpha1.ConfigMapReference{
+					Name:                "central-config",
+					Namespace:           "default",
+					KubeconfigSecretRef: &syncv1alpha1.SecretKeyReference{Name: secretName, Key: "kubeconfig"},
+				},
+				TargetNamespaces: []string{targetNamespace},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+		controllerReconciler := newReconciler()
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "central-config", Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Data).To(Equal(map[string]string{"source": "central"}))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionTypeMasterAvailable)).To(BeTrue())
+
+		By("reporting the master unavailable when the cluster cannot be reached")
+		resource.Spec.MasterConfigMap.KubeconfigSecretRef.Name = "missing-kubeconfig"
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		condition := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeMasterAvailable)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(ConditionReasonMasterClusterUnreachable))
+	})
+})
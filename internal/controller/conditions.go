@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+// setSyncConditions sets the Ready, Degraded and Progressing conditions from
+// the outcome of a sync. Ready is only true when every target is in sync.
+func (r *ConfigMapSyncerReconciler) setSyncConditions(
+	configMapSyncer syncv1alpha1.Syncer,
+	result *syncResult,
+) {
+	total := result.total()
+	failed := result.failed()
+
+	if failed > 0 {
+		message := fmt.Sprintf("%d/%d targets failed", failed, total)
+		if forbidden := result.forbidden(); forbidden > 0 {
+			message = fmt.Sprintf("%s, %d forbidden", message, forbidden)
+		}
+		message += skippedSuffix(result)
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonTargetsFailed,
+			Message: message,
+		})
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  ConditionReasonTargetsFailed,
+			Message: message,
+		})
+	} else {
+		message := fmt.Sprintf("%d/%d targets synced", total, total) + skippedSuffix(result)
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  ConditionReasonSyncSuccess,
+			Message: message,
+		})
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeDegraded,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonAllTargetsSynced,
+			Message: message,
+		})
+	}
+
+	if result.updated > 0 {
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeProgressing,
+			Status:  metav1.ConditionTrue,
+			Reason:  ConditionReasonTargetsUpdated,
+			Message: fmt.Sprintf("%d/%d targets updated", result.updated, total),
+		})
+	} else {
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeProgressing,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonUpToDate,
+			Message: "No targets needed to be updated",
+		})
+	}
+}
+
+// skippedSuffix returns the part of a condition message reporting skipped namespaces
+func skippedSuffix(result *syncResult) string {
+	if skipped := result.skipped(); skipped > 0 {
+		return fmt.Sprintf(", %d namespaces skipped", skipped)
+	}
+	return ""
+}
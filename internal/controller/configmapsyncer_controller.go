@@ -19,26 +19,43 @@ package controller
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/clusters"
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+	"github.com/devShahriar/configmap-sync-controller/internal/sources"
 )
 
 const (
 	// ConditionTypeReady is the type for the ready condition
 	ConditionTypeReady = "Ready"
 
+	// ConditionTypeDegraded is the type for the condition reporting failed targets
+	ConditionTypeDegraded = "Degraded"
+
+	// ConditionTypeProgressing is the type for the condition reporting targets being updated
+	ConditionTypeProgressing = "Progressing"
+
+	// ConditionTypeMasterAvailable is the type for the condition reporting whether the master ConfigMap can be read
+	ConditionTypeMasterAvailable = "MasterAvailable"
+
 	// ConditionReasonSyncSuccess is the reason for a successful sync
 	ConditionReasonSyncSuccess = "SyncSuccess"
 
@@ -48,6 +65,27 @@ const (
 	// ConditionReasonMasterConfigMapNotFound is the reason when the master ConfigMap is not found
 	ConditionReasonMasterConfigMapNotFound = "MasterConfigMapNotFound"
 
+	// ConditionReasonInvalidSpec is the reason when the ConfigMapSyncer spec cannot be acted on
+	ConditionReasonInvalidSpec = "InvalidSpec"
+
+	// ConditionReasonMasterClusterUnreachable is the reason when the remote cluster of the master ConfigMap cannot be reached
+	ConditionReasonMasterClusterUnreachable = "MasterClusterUnreachable"
+
+	// ConditionReasonMasterConfigMapFound is the reason when the master ConfigMap was read
+	ConditionReasonMasterConfigMapFound = "MasterConfigMapFound"
+
+	// ConditionReasonTargetsFailed is the reason when some targets could not be synced
+	ConditionReasonTargetsFailed = "TargetsFailed"
+
+	// ConditionReasonAllTargetsSynced is the reason when every target is in sync
+	ConditionReasonAllTargetsSynced = "AllTargetsSynced"
+
+	// ConditionReasonTargetsUpdated is the reason when targets were created or updated by the last sync
+	ConditionReasonTargetsUpdated = "TargetsUpdated"
+
+	// ConditionReasonUpToDate is the reason when the last sync did not need to change any target
+	ConditionReasonUpToDate = "UpToDate"
+
 	// SyncStatusPending indicates that the sync is pending
 	SyncStatusPending = "Pending"
 
@@ -57,23 +95,86 @@ const (
 	// SyncStatusFailed indicates that the sync failed
 	SyncStatusFailed = "Failed"
 
+	// SyncStatusPruned indicates that the target is no longer selected and was pruned
+	SyncStatusPruned = "Pruned"
+
+	// SyncStatusForbidden indicates that the ConfigMapSyncer is not allowed to write the target
+	SyncStatusForbidden = "Forbidden"
+
+	// SyncStatusSkipped indicates that the target namespace opted out of the sync
+	SyncStatusSkipped = "Skipped"
+
 	// MergeStrategyReplace replaces the target ConfigMap with the master ConfigMap
 	MergeStrategyReplace = "Replace"
 
 	// MergeStrategyMerge merges the master ConfigMap with the target ConfigMap
 	MergeStrategyMerge = "Merge"
 
+	// PrunePolicyDelete deletes targets that are no longer selected
+	PrunePolicyDelete = "Delete"
+
+	// PrunePolicyRelease removes the controller's labels and annotations from targets that are no longer selected
+	PrunePolicyRelease = "Release"
+
 	// FinalizerName is the name of the finalizer
 	FinalizerName = "configmapsyncer.conf-sync.com/finalizer"
 
 	// SourceConfigMapLabel is the label key for the source ConfigMap
 	SourceConfigMapLabel = "configmapsyncer.conf-sync.com/source"
+
+	// ContentHashAnnotation is the annotation key for the hash of the content synced into a target
+	ContentHashAnnotation = "configmapsyncer.conf-sync.com/content-hash"
+
+	// SourceResourceVersionAnnotation is the annotation key for the master ConfigMap resourceVersion a target was synced from
+	SourceResourceVersionAnnotation = "configmapsyncer.conf-sync.com/source-resource-version"
+
+	// CreatedByControllerAnnotation marks targets the controller created. Only these are deleted by the Delete prune policy.
+	CreatedByControllerAnnotation = "configmapsyncer.conf-sync.com/created-by-controller"
 )
 
 // ConfigMapSyncerReconciler reconciles a ConfigMapSyncer object
 type ConfigMapSyncerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// APIReader reads directly from the API server. It is used for master
+	// ConfigMaps and selector-matched targets, which are not in the cache.
+	// The cached Client is used when it is nil.
+	APIReader client.Reader
+
+	// Recorder emits events on the ConfigMapSyncer. Events are not emitted when it is nil.
+	Recorder record.EventRecorder
+
+	// Config holds the concurrency, backoff and authorization settings. The
+	// defaults are used when it is left empty.
+	Config config.ControllerConfig
+
+	// TrustRequesters is set when the mutating webhook maintains the requester
+	// annotation. Otherwise the annotation could be forged and only the target
+	// namespace's AcceptFromAnnotation authorizes writes.
+	TrustRequesters bool
+
+	// Clusters holds the connections to the remote clusters in spec.clusters.
+	// An empty registry is created when it is nil.
+	Clusters *clusters.Registry
+
+	// remoteMasters watches masters in remote clusters. It is set up by
+	// SetupWithManager, remote masters are only re-read on the sync interval
+	// without it.
+	remoteMasters *remoteMasterWatches
+
+	// Git fetches masters from Git repositories. A Git source caching its
+	// mirrors in the temporary directory is created when it is nil.
+	Git *sources.Git
+
+	// HTTP fetches masters from HTTP(S) endpoints. One is created when it is nil.
+	HTTP *sources.HTTP
+
+	// OCI pulls masters from OCI registries. One is created when it is nil.
+	OCI *sources.OCI
+
+	// Clock tells the time sync windows are evaluated at. The real clock is used when it is nil.
+	Clock clock.PassiveClock
 }
 
 // +kubebuilder:rbac:groups=conf-sync.com,resources=configmapsyncers,verbs=get;list;watch;create;update;patch;delete
@@ -81,6 +182,7 @@ type ConfigMapSyncerReconciler struct {
 // +kubebuilder:rbac:groups=conf-sync.com,resources=configmapsyncers/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -104,140 +206,463 @@ func (r *ConfigMapSyncerReconciler) Reconcile(
 		}
 		// Error reading the object - requeue the request.
 		logger.Error(err, "Failed to get ConfigMapSyncer")
-		return ctrl.Result{}, err
+		return ctrl.Result{}, newTransientError("get ConfigMapSyncer", err)
 	}
 
+	return r.reconcileSyncer(ctx, configMapSyncer)
+}
+
+// reconcileSyncer syncs the master ConfigMap of a ConfigMapSyncer or
+// ClusterConfigMapSyncer to its targets and records the outcome in its status
+func (r *ConfigMapSyncerReconciler) reconcileSyncer(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Keep the object as read so that finalizer and status changes can be sent as patches
+	original := configMapSyncer.DeepCopyObject().(syncv1alpha1.Syncer)
+
 	// Initialize status if it's empty
-	if configMapSyncer.Status.Conditions == nil {
-		configMapSyncer.Status.Conditions = []metav1.Condition{}
+	if configMapSyncer.GetStatus().Conditions == nil {
+		configMapSyncer.GetStatus().Conditions = []metav1.Condition{}
 	}
 
 	// Add finalizer if it doesn't exist
 	if !controllerutil.ContainsFinalizer(configMapSyncer, FinalizerName) {
 		controllerutil.AddFinalizer(configMapSyncer, FinalizerName)
-		if err := r.Update(ctx, configMapSyncer); err != nil {
+		if err := r.Patch(ctx, configMapSyncer, client.MergeFrom(original)); err != nil {
 			logger.Error(err, "Failed to add finalizer")
-			return ctrl.Result{}, err
+			return ctrl.Result{}, newTransientError("add finalizer", err)
 		}
 		return ctrl.Result{Requeue: true}, nil
 	}
 
 	// Check if the ConfigMapSyncer is being deleted
-	if !configMapSyncer.DeletionTimestamp.IsZero() {
+	if !configMapSyncer.GetDeletionTimestamp().IsZero() {
 		return r.handleDeletion(ctx, configMapSyncer)
 	}
 
+	// A namespaced ConfigMapSyncer may only read masters and kubeconfigs from its own namespace,
+	// Secret masters need to be allowed by the admin
+	if err := r.validateScope(configMapSyncer); err != nil {
+		logger.Error(err, "Refusing to sync ConfigMapSyncer")
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonInvalidSpec,
+			Message: err.Error(),
+		})
+		configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+		if updateErr := r.patchStatus(ctx, original, configMapSyncer); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, reconcileError(err)
+	}
+
+	// A dry run reports its plan on top of the status of the last sync that wrote the targets
+	dryRun := r.dryRun(configMapSyncer)
+	var lastWritten *syncv1alpha1.ConfigMapSyncerStatus
+	if dryRun {
+		lastWritten = configMapSyncer.GetStatus().DeepCopy()
+	}
+
 	// Get the master ConfigMap
-	masterConfigMap := &corev1.ConfigMap{}
-	masterConfigMapKey := types.NamespacedName{
-		Name:      configMapSyncer.Spec.MasterConfigMap.Name,
-		Namespace: configMapSyncer.Spec.MasterConfigMap.Namespace,
+	masterConfigMap, availability, err := r.loadMaster(ctx, configMapSyncer)
+	if err != nil {
+		logger.Error(err, "Failed to get master ConfigMap")
+		return ctrl.Result{}, err
+	}
+	r.setCondition(configMapSyncer, availability)
+	if availability.Status == metav1.ConditionFalse {
+		// Master not available, update status and requeue. Targets are left as they are.
+		logger.Info("Master ConfigMap not available", "reason", availability.Reason, "message", availability.Message)
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  availability.Reason,
+			Message: availability.Message,
+		})
+		configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+		if err := r.patchStatus(ctx, original, configMapSyncer); err != nil {
+			logger.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
+		}
+		// Requeue after 1 minute
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
 	}
 
-	if err := r.Get(ctx, masterConfigMapKey, masterConfigMap); err != nil {
-		if errors.IsNotFound(err) {
-			// Master ConfigMap not found, update status and requeue
-			logger.Info("Master ConfigMap not found", "configMap", masterConfigMapKey)
+	// Record the master content in the revision history, a pinned revision is propagated instead of the master
+	propagated, err := r.revisionToPropagate(ctx, configMapSyncer, masterConfigMap)
+	if err != nil {
+		logger.Error(err, "Failed to determine the revision to propagate")
+		reason := ConditionReasonSyncFailed
+		if IsValidationError(err) {
+			reason = ConditionReasonInvalidSpec
+		}
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: fmt.Sprintf("Failed to determine the revision to propagate: %v", err),
+		})
+		configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+		if updateErr := r.patchStatus(ctx, original, configMapSyncer); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, reconcileError(err)
+	}
+	if propagated == nil {
+		// Nothing was approved yet, targets are not written until then
+		r.setApprovalConditions(configMapSyncer)
+		configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+		if err := r.patchStatus(ctx, original, configMapSyncer); err != nil {
+			logger.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: syncInterval(configMapSyncer)}, nil
+	}
+	masterResourceVersion := masterConfigMap.ResourceVersion
+	masterConfigMap = propagated
+
+	// Defer writes while the sync windows are closed, a dry run plans them anyway
+	var windowWait time.Duration
+	if !dryRun {
+		windowWait, err = r.waitForWindow(configMapSyncer)
+	}
+	if err != nil || windowWait > 0 {
+		if err != nil {
+			logger.Error(err, "Invalid sync windows")
 			r.setCondition(configMapSyncer, metav1.Condition{
 				Type:    ConditionTypeReady,
 				Status:  metav1.ConditionFalse,
-				Reason:  ConditionReasonMasterConfigMapNotFound,
-				Message: fmt.Sprintf("Master ConfigMap %s not found", masterConfigMapKey),
+				Reason:  ConditionReasonInvalidSpec,
+				Message: err.Error(),
 			})
-			if err := r.Status().Update(ctx, configMapSyncer); err != nil {
-				logger.Error(err, "Failed to update ConfigMapSyncer status")
-				return ctrl.Result{}, err
-			}
-			// Requeue after 1 minute
-			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		} else {
+			logger.Info("Sync windows are closed", "next", configMapSyncer.GetStatus().NextSyncWindow)
 		}
-		// Error reading the object - requeue the request.
-		logger.Error(err, "Failed to get master ConfigMap")
+		configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+		if updateErr := r.patchStatus(ctx, original, configMapSyncer); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: windowWait}, reconcileError(err)
+	}
+
+	// Load the statuses of the previous sync, they may be stored in reports
+	previous, err := r.previousSyncStatuses(ctx, configMapSyncer)
+	if err != nil {
+		logger.Error(err, "Failed to read previous sync statuses")
 		return ctrl.Result{}, err
 	}
 
-	// Sync ConfigMaps
-	syncResult, err := r.syncConfigMaps(ctx, configMapSyncer, masterConfigMap)
+	// A new master content starts a new rollout
+	beginRollout(configMapSyncer, masterConfigMap)
+
+	// Sync ConfigMaps in the local cluster
+	local := r.localCluster()
+	if dryRun {
+		local = local.asDryRun()
+	}
+	result, err := r.syncConfigMaps(ctx, configMapSyncer, masterConfigMap, local)
 	if err != nil {
 		logger.Error(err, "Failed to sync ConfigMaps")
+		reason := ConditionReasonSyncFailed
+		if IsValidationError(err) {
+			reason = ConditionReasonInvalidSpec
+		}
 		r.setCondition(configMapSyncer, metav1.Condition{
 			Type:    ConditionTypeReady,
 			Status:  metav1.ConditionFalse,
-			Reason:  ConditionReasonSyncFailed,
+			Reason:  reason,
 			Message: fmt.Sprintf("Failed to sync ConfigMaps: %v", err),
 		})
-		if updateErr := r.Status().Update(ctx, configMapSyncer); updateErr != nil {
-			logger.Error(updateErr, "Failed to update ConfigMapSyncer status")
+		configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+		if !dryRun {
+			statuses := r.pruneOutOfScopeTargets(ctx, configMapSyncer, previous, map[string]*targetCluster{local.name: local})
+			if recordErr := r.recordSyncStatuses(ctx, configMapSyncer, statuses); recordErr != nil {
+				logger.Error(recordErr, "Failed to record sync statuses")
+			}
+		}
+		if updateErr := r.patchStatus(ctx, original, configMapSyncer); updateErr != nil {
+			logger.Error(updateErr, "Failed to update status")
 			return ctrl.Result{}, updateErr
 		}
+		return ctrl.Result{}, reconcileError(err)
+	}
+
+	// Propagate to remote clusters
+	clusters := r.syncRemoteClusters(ctx, configMapSyncer, masterConfigMap, result)
+	clusters[local.name] = local
+
+	// Prune targets that were synced before but are no longer selected
+	r.pruneStaleTargets(ctx, configMapSyncer, previous, result, clusters)
+
+	// A dry run stops here and reports its plan instead of the outcome
+	if dryRun {
+		*configMapSyncer.GetStatus() = *lastWritten
+		return r.finishDryRun(ctx, original, configMapSyncer, result)
+	}
+
+	// Move a rollout on to its next wave once the current one is healthy
+	rolloutRequeue, err := r.advanceRollout(ctx, configMapSyncer, result, clusters)
+	if err != nil {
+		logger.Error(err, "Failed to check the health of the rollout wave")
+		return ctrl.Result{}, err
+	}
+
+	// Store the per-target statuses inline or in reports
+	if err := r.recordSyncStatuses(ctx, configMapSyncer, result.statuses); err != nil {
+		logger.Error(err, "Failed to record sync statuses")
 		return ctrl.Result{}, err
 	}
 
 	// Update status
 	now := metav1.NewTime(time.Now())
-	configMapSyncer.Status.LastSyncTime = &now
-	configMapSyncer.Status.SyncStatuses = syncResult
-
-	r.setCondition(configMapSyncer, metav1.Condition{
-		Type:    ConditionTypeReady,
-		Status:  metav1.ConditionTrue,
-		Reason:  ConditionReasonSyncSuccess,
-		Message: "Successfully synced ConfigMaps",
-	})
-
-	if err := r.Status().Update(ctx, configMapSyncer); err != nil {
-		logger.Error(err, "Failed to update ConfigMapSyncer status")
+	configMapSyncer.GetStatus().LastSyncTime = &now
+	configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+	configMapSyncer.GetStatus().MasterResourceVersion = masterResourceVersion
+	configMapSyncer.GetStatus().TargetsTotal = int32(result.total())
+	configMapSyncer.GetStatus().TargetsFailed = int32(result.failed())
+	configMapSyncer.GetStatus().TargetsSynced = configMapSyncer.GetStatus().TargetsTotal - configMapSyncer.GetStatus().TargetsFailed -
+		int32(result.pending())
+	configMapSyncer.GetStatus().NamespacesSkipped = int32(result.skipped())
+	r.setSyncConditions(configMapSyncer, result)
+	r.setRolloutConditions(configMapSyncer, result)
+	r.setApprovalConditions(configMapSyncer)
+	clearDryRun(configMapSyncer)
+
+	if err := r.patchStatus(ctx, original, configMapSyncer); err != nil {
+		logger.Error(err, "Failed to update status")
 		return ctrl.Result{}, err
 	}
 
-	// Use sync interval from spec, default to 300 seconds (5 minutes)
-	interval := time.Duration(configMapSyncer.Spec.SyncInterval) * time.Second
-	if interval == 0 {
-		interval = 300 * time.Second
+	// Publish the name of the current version of immutable targets
+	if err := r.publishCurrentVersion(ctx, configMapSyncer, result); err != nil {
+		logger.Error(err, "Failed to publish the current target version")
+		return ctrl.Result{}, err
+	}
+
+	interval := syncInterval(configMapSyncer)
+	if rolloutRequeue > 0 && rolloutRequeue < interval {
+		interval = rolloutRequeue
 	}
 
 	// Requeue after the specified interval
 	return ctrl.Result{RequeueAfter: interval}, nil
 }
 
-// handleDeletion handles the deletion of the ConfigMapSyncer resource
+// syncInterval returns the sync interval from the spec, 300 seconds (5 minutes) by default
+func syncInterval(configMapSyncer syncv1alpha1.Syncer) time.Duration {
+	interval := time.Duration(configMapSyncer.GetSpec().SyncInterval) * time.Second
+	if interval == 0 {
+		interval = 300 * time.Second
+	}
+	return interval
+}
+
+// handleDeletion handles the deletion of the syncer
 func (r *ConfigMapSyncerReconciler) handleDeletion(
 	ctx context.Context,
-	configMapSyncer *syncv1alpha1.ConfigMapSyncer,
+	configMapSyncer syncv1alpha1.Syncer,
 ) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
-	logger.Info("Handling deletion of ConfigMapSyncer", "name", configMapSyncer.Name)
+	logger.Info("Handling deletion", "kind", kindOf(configMapSyncer), "name", configMapSyncer.GetName())
+	r.stopWatchingRemoteMaster(configMapSyncer)
+	r.evictClusters(configMapSyncer)
+	r.forgetSource(configMapSyncer)
 
 	// Remove finalizer
+	original := configMapSyncer.DeepCopyObject().(syncv1alpha1.Syncer)
 	controllerutil.RemoveFinalizer(configMapSyncer, FinalizerName)
-	if err := r.Update(ctx, configMapSyncer); err != nil {
+	if err := r.Patch(ctx, configMapSyncer, client.MergeFrom(original)); err != nil {
 		logger.Error(err, "Failed to remove finalizer")
-		return ctrl.Result{}, err
+		return ctrl.Result{}, newTransientError("remove finalizer", err)
 	}
 
 	return ctrl.Result{}, nil
 }
 
-// syncConfigMaps syncs the master ConfigMap to target ConfigMaps
+// syncResult summarizes a sync of the master ConfigMap to all of its targets
+type syncResult struct {
+	// statuses holds one entry per target, including the ones that failed
+	statuses []syncv1alpha1.SyncStatus
+
+	// updated is the number of targets created or updated by this sync
+	updated int
+
+	// incompleteNamespaces holds the namespaces whose targets could not be listed.
+	// Targets in them are not pruned since it is unknown whether they are still selected.
+	incompleteNamespaces map[string]bool
+
+	// skippedNamespaces holds the namespaces that opted out of the sync. Targets
+	// in them are left alone, they are neither updated nor pruned.
+	skippedNamespaces map[string]bool
+
+	// incompleteClusters holds the remote clusters that could not be synced.
+	// Targets in them are not pruned.
+	incompleteClusters map[string]bool
+
+	// heldNamespaces holds the namespaces of later waves of a rollout. Targets
+	// in them are neither updated nor pruned.
+	heldNamespaces map[string]bool
+
+	// waveNamespaces holds the namespaces of the wave being rolled out
+	waveNamespaces map[string]bool
+
+	// plan holds the writes and prunes planned by a dry run
+	plan []syncv1alpha1.PlannedChange
+
+	// versions holds the older versions and the aliases of immutable
+	// targets. They are garbage-collected separately and not pruned.
+	versions map[targetKey]bool
+}
+
+// newSyncResult returns an empty syncResult
+func newSyncResult() *syncResult {
+	return &syncResult{
+		incompleteNamespaces: map[string]bool{},
+		skippedNamespaces:    map[string]bool{},
+		incompleteClusters:   map[string]bool{},
+		heldNamespaces:       map[string]bool{},
+		waveNamespaces:       map[string]bool{},
+		versions:             map[targetKey]bool{},
+	}
+}
+
+// merge adds the outcome of a sync to another cluster to the result
+func (s *syncResult) merge(other *syncResult) {
+	s.statuses = append(s.statuses, other.statuses...)
+	s.updated += other.updated
+	s.plan = append(s.plan, other.plan...)
+	for key := range other.incompleteNamespaces {
+		s.incompleteNamespaces[key] = true
+	}
+	for key := range other.skippedNamespaces {
+		s.skippedNamespaces[key] = true
+	}
+	for name := range other.incompleteClusters {
+		s.incompleteClusters[name] = true
+	}
+	for key := range other.heldNamespaces {
+		s.heldNamespaces[key] = true
+	}
+	for key := range other.waveNamespaces {
+		s.waveNamespaces[key] = true
+	}
+	for key := range other.versions {
+		s.versions[key] = true
+	}
+}
+
+// total returns the number of targets that are currently selected, not
+// counting skipped namespaces
+func (s *syncResult) total() int {
+	total := 0
+	for _, status := range s.statuses {
+		if status.Status != SyncStatusPruned && status.Status != SyncStatusSkipped {
+			total++
+		}
+	}
+	return total
+}
+
+// skipped returns the number of namespaces that opted out of the sync
+func (s *syncResult) skipped() int {
+	return len(s.skippedNamespaces)
+}
+
+// failed returns the number of targets that could not be synced, including
+// the ones that were forbidden
+func (s *syncResult) failed() int {
+	failed := 0
+	for _, status := range s.statuses {
+		if status.Status == SyncStatusFailed || status.Status == SyncStatusForbidden {
+			failed++
+		}
+	}
+	return failed
+}
+
+// pending returns the number of targets held back by a rollout
+func (s *syncResult) pending() int {
+	pending := 0
+	for _, status := range s.statuses {
+		if status.Status == SyncStatusPending {
+			pending++
+		}
+	}
+	return pending
+}
+
+// forbidden returns the number of targets the syncer is not allowed to write
+func (s *syncResult) forbidden() int {
+	forbidden := 0
+	for _, status := range s.statuses {
+		if status.Status == SyncStatusForbidden {
+			forbidden++
+		}
+	}
+	return forbidden
+}
+
+// syncConfigMaps syncs the master ConfigMap to target ConfigMaps in the given
+// cluster. Failures of individual targets are recorded in the result, only
+// errors that prevent the sync as a whole are returned.
 func (r *ConfigMapSyncerReconciler) syncConfigMaps(
 	ctx context.Context,
-	configMapSyncer *syncv1alpha1.ConfigMapSyncer,
+	configMapSyncer syncv1alpha1.Syncer,
 	masterConfigMap *corev1.ConfigMap,
-) ([]syncv1alpha1.SyncStatus, error) {
-	logger := log.FromContext(ctx)
-	var syncStatuses []syncv1alpha1.SyncStatus
+	cluster *targetCluster,
+) (*syncResult, error) {
+	logger := log.FromContext(ctx).WithValues("cluster", cluster.displayName())
+	kind := targetKind(configMapSyncer)
+	result := newSyncResult()
+	defer func() {
+		for i := range result.statuses {
+			result.statuses[i].Cluster = cluster.name
+			result.statuses[i].Kind = statusKind(kind)
+		}
+	}()
+
+	// Parse the target selector up front, an invalid selector will not fix itself on retry
+	var targetSelector labels.Selector
+	if configMapSyncer.GetSpec().TargetSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(configMapSyncer.GetSpec().TargetSelector)
+		if err != nil {
+			return nil, newValidationError("spec.targetSelector", err)
+		}
+		targetSelector = selector
+	}
+
+	// Parse the selector of the workloads to restart as well
+	var restartSelector labels.Selector
+	if restart := configMapSyncer.GetSpec().RestartWorkloads; restart != nil {
+		restartSelector = labels.Everything()
+		if restart.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(restart.Selector)
+			if err != nil {
+				return nil, newValidationError("spec.restartWorkloads.selector", err)
+			}
+			restartSelector = selector
+		}
+	}
 
 	// Get target namespaces
-	targetNamespaces := configMapSyncer.Spec.TargetNamespaces
+	origin := masterOrigin(configMapSyncer)
+	targetNamespaces := configMapSyncer.GetSpec().TargetNamespaces
 	if len(targetNamespaces) == 0 {
 		// If no target namespaces are specified, get all namespaces
-		namespaceList := &corev1.NamespaceList{}
-		if err := r.List(ctx, namespaceList); err != nil {
-			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		namespaceList := namespaceMetadataList()
+		if err := cluster.client.List(ctx, namespaceList); err != nil {
+			return nil, newTransientError("list namespaces", err)
 		}
 		for _, ns := range namespaceList.Items {
 			// Skip the namespace of the master ConfigMap
-			if ns.Name != masterConfigMap.Namespace {
+			if !holdsMaster(cluster, origin, ns.Name, masterConfigMap.Namespace) {
 				targetNamespaces = append(targetNamespaces, ns.Name)
 			}
 		}
@@ -245,46 +670,166 @@ func (r *ConfigMapSyncerReconciler) syncConfigMaps(
 
 	// Determine target ConfigMap name
 	targetConfigMapName := masterConfigMap.Name
-	if configMapSyncer.Spec.TargetConfigMapName != "" {
-		targetConfigMapName = configMapSyncer.Spec.TargetConfigMapName
+	if configMapSyncer.GetSpec().TargetConfigMapName != "" {
+		targetConfigMapName = configMapSyncer.GetSpec().TargetConfigMapName
+	}
+	baseTargetName := targetConfigMapName
+
+	// Determine merge strategy
+	mergeStrategy := mergeStrategyOf(configMapSyncer)
+	if spec := configMapSyncer.GetSpec().MergeStrategy; spec != "" && spec != mergeStrategy {
+		logger.Info("Unknown merge strategy, using Merge", "strategy", spec)
+	}
+
+	// Hash the content every target should carry once it is in sync
+	contentHash := computeContentHash(masterConfigMap, configMapSyncer)
+
+	// Immutable targets get a new version named after the content
+	immutable := configMapSyncer.GetSpec().ImmutableTargets != nil && targetSelector == nil
+	if immutable {
+		targetConfigMapName = versionedTargetName(baseTargetName, contentHash)
+	}
+
+	// Namespaces of later waves of a rollout are held back
+	waves, err := r.assignWaves(ctx, configMapSyncer, cluster, targetNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	secretType := targetSecretType(configMapSyncer)
+	showValues := showsValues(configMapSyncer)
+
+	// Namespaced syncers may only write targets their namespace is trusted with.
+	// Remote clusters are written with the credentials of their kubeconfig.
+	var authorizer *tenantAuthorizer
+	if cluster.isLocal() {
+		authorizer = r.newTenantAuthorizer(configMapSyncer)
+	}
+
+	// Restart the workloads consuming a target once it is in sync
+	restart := func(syncStatus *syncv1alpha1.SyncStatus, written bool) {
+		if restartSelector == nil || cluster.dryRun {
+			return
+		}
+		key := types.NamespacedName{Name: syncStatus.ConfigMapName, Namespace: syncStatus.Namespace}
+		restarted, err := r.restartWorkloads(ctx, configMapSyncer, cluster, authorizer, restartSelector, kind, key, contentHash, written)
+		if err != nil {
+			syncStatus.Status = SyncStatusFailed
+			syncStatus.Message = fmt.Sprintf("%s: %v", restartFailedMessage, err)
+			return
+		}
+		if restarted > 0 {
+			syncStatus.Message = fmt.Sprintf("Restarted %d workloads", restarted)
+		}
+	}
+
+	// Workloads are only restarted after a write, or to retry a restart that failed after one
+	previousRestartFailures := failedRestarts(configMapSyncer.GetStatus())
+	restartFailed := func(key types.NamespacedName) bool {
+		return previousRestartFailures[targetKey{cluster: cluster.name, kind: kind, NamespacedName: key}]
+	}
+
+	// Delete old versions of immutable targets once the current one is in sync
+	collect := func(syncStatus *syncv1alpha1.SyncStatus) {
+		if !immutable || syncStatus.Status != SyncStatusSynced {
+			return
+		}
+		key := types.NamespacedName{Name: syncStatus.ConfigMapName, Namespace: syncStatus.Namespace}
+		if err := r.collectVersions(ctx, configMapSyncer, cluster, authorizer, masterConfigMap, kind, baseTargetName, key, result); err != nil {
+			logger.Error(err, "Failed to collect old versions", "kind", kind, "namespace", key.Namespace, "name", key.Name)
+			syncStatus.Status = SyncStatusFailed
+			syncStatus.Message = fmt.Sprintf("Failed to collect old versions: %v", err)
+		}
 	}
 
 	// Process each target namespace
 	for _, namespace := range targetNamespaces {
 		// Skip the namespace of the master ConfigMap
-		if namespace == masterConfigMap.Namespace {
+		if holdsMaster(cluster, origin, namespace, masterConfigMap.Namespace) {
 			continue
 		}
 
-		var targetConfigMaps []corev1.ConfigMap
+		// Leave namespaces alone whose owners opted out or restricted the sources
+		skipReason, err := r.skipNamespaceReason(ctx, cluster, namespace, masterConfigMap, origin)
+		if err != nil {
+			logger.Error(err, "Failed to get namespace", "namespace", namespace)
+			result.incompleteNamespaces[namespaceKey(cluster.name, namespace)] = true
+			result.statuses = append(result.statuses, syncv1alpha1.SyncStatus{
+				Namespace: namespace,
+				Status:    SyncStatusFailed,
+				Message:   fmt.Sprintf("Failed to get namespace: %v", err),
+			})
+			continue
+		}
+		if skipReason != "" {
+			logger.Info("Skipping namespace", "namespace", namespace, "reason", skipReason)
+			result.skippedNamespaces[namespaceKey(cluster.name, namespace)] = true
+			syncStatus := syncv1alpha1.SyncStatus{
+				Namespace: namespace,
+				Status:    SyncStatusSkipped,
+				Message:   skipReason,
+			}
+			if targetSelector == nil {
+				syncStatus.ConfigMapName = targetConfigMapName
+			}
+			result.statuses = append(result.statuses, syncStatus)
+			continue
+		}
 
-		// If targetSelector is specified, find ConfigMaps matching the selector
-		if configMapSyncer.Spec.TargetSelector != nil {
-			selector, err := metav1.LabelSelectorAsSelector(
-				configMapSyncer.Spec.TargetSelector,
-			)
-			if err != nil {
-				logger.Error(err, "Failed to parse label selector")
-				continue
+		// Hold back namespaces of later waves of a rollout
+		if wave, held := waves.held(namespace); held {
+			result.heldNamespaces[namespaceKey(cluster.name, namespace)] = true
+			syncStatus := syncv1alpha1.SyncStatus{
+				Namespace: namespace,
+				Status:    SyncStatusPending,
+				Message:   fmt.Sprintf("Waiting for rollout wave %d", wave+1),
 			}
+			if targetSelector == nil {
+				syncStatus.ConfigMapName = targetConfigMapName
+			}
+			result.statuses = append(result.statuses, syncStatus)
+			continue
+		}
+		if waves.current(namespace) {
+			result.waveNamespaces[namespaceKey(cluster.name, namespace)] = true
+		}
 
-			configMapList := &corev1.ConfigMapList{}
-			if err := r.List(ctx, configMapList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
-				logger.Error(err, "Failed to list ConfigMaps", "namespace", namespace)
+		var targetConfigMaps []corev1.ConfigMap
+
+		// If targetSelector is specified, find targets matching the selector
+		if targetSelector != nil {
+			targets, err := listTargets(ctx, cluster.reader, namespace, targetSelector, kind)
+			if err != nil {
+				logger.Error(err, "Failed to list targets", "kind", kind, "namespace", namespace)
+				result.incompleteNamespaces[namespaceKey(cluster.name, namespace)] = true
+				result.statuses = append(result.statuses, syncv1alpha1.SyncStatus{
+					Namespace: namespace,
+					Status:    SyncStatusFailed,
+					Message:   fmt.Sprintf("Failed to list target %ss: %v", kind, err),
+				})
 				continue
 			}
 
-			targetConfigMaps = configMapList.Items
+			targetConfigMaps = targets
 		} else {
-			// If no targetSelector is specified, create/update a ConfigMap with the specified name
-			targetConfigMap := &corev1.ConfigMap{}
-			err := r.Get(ctx, types.NamespacedName{Name: targetConfigMapName, Namespace: namespace}, targetConfigMap)
+			// If no targetSelector is specified, create/update a target with the specified name
+			targetKey := types.NamespacedName{Name: targetConfigMapName, Namespace: namespace}
+			targetConfigMap, err := readAsConfigMap(ctx, cluster.client, targetKey, kind)
+			if errors.IsNotFound(err) && cluster.cached {
+				// Only labeled targets are cached, check whether an unlabeled one already exists
+				targetConfigMap, err = readAsConfigMap(ctx, cluster.reader, targetKey, kind)
+			}
 			if err != nil {
 				if !errors.IsNotFound(err) {
-					logger.Error(err, "Failed to get target ConfigMap", "namespace", namespace, "name", targetConfigMapName)
+					logger.Error(err, "Failed to get target", "kind", kind, "namespace", namespace, "name", targetConfigMapName)
+					result.statuses = append(result.statuses, syncv1alpha1.SyncStatus{
+						ConfigMapName: targetConfigMapName,
+						Namespace:     namespace,
+						Status:        SyncStatusFailed,
+						Message:       fmt.Sprintf("Failed to get %s: %v", kind, err),
+					})
 					continue
 				}
-				// ConfigMap doesn't exist, create a new one
+				// Target doesn't exist, create a new one
 				targetConfigMap = &corev1.ConfigMap{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      targetConfigMapName,
@@ -308,6 +853,48 @@ func (r *ConfigMapSyncerReconciler) syncConfigMaps(
 				Status:        SyncStatusPending,
 			}
 
+			// Check that the syncer may write the target before touching it. Targets
+			// that are already in sync are checked as well, so that revoked access
+			// shows up as Forbidden.
+			exists := targetConfigMap.ResourceVersion != ""
+			verb := "update"
+			if !exists {
+				verb = "create"
+			}
+			targetKey := types.NamespacedName{Name: targetConfigMap.Name, Namespace: targetConfigMap.Namespace}
+			reason, err := authorizer.authorize(ctx, targetKey, verb)
+			if err != nil {
+				logger.Error(err, "Failed to authorize target", "kind", kind, "namespace", targetKey.Namespace, "name", targetKey.Name)
+				syncStatus.Status = SyncStatusFailed
+				syncStatus.Message = fmt.Sprintf("Failed to authorize %s: %v", kind, err)
+				result.statuses = append(result.statuses, syncStatus)
+				continue
+			}
+			if reason != "" {
+				logger.Info("Not allowed to write target", "kind", kind, "namespace", targetKey.Namespace, "name", targetKey.Name, "reason", reason)
+				syncStatus.Status = SyncStatusForbidden
+				syncStatus.Message = reason
+				r.event(configMapSyncer, corev1.EventTypeWarning, EventReasonTargetForbidden,
+					"Not allowed to write %s %s: %s", kind, targetKey, reason)
+				result.statuses = append(result.statuses, syncStatus)
+				continue
+			}
+
+			// Targets read from the cache that carry the current hash and still hold
+			// its content are in sync, there is no need to write them again
+			if exists && targetConfigMap.Annotations[ContentHashAnnotation] == contentHash &&
+				holdsContent(&targetConfigMap, masterConfigMap, configMapSyncer) {
+				logger.Info("Target is already in sync", "kind", kind, "namespace", targetConfigMap.Namespace, "name", targetConfigMap.Name)
+				syncStatus.Status = SyncStatusSynced
+				syncStatus.LastSyncTime = &metav1.Time{Time: time.Now()}
+				if restartFailed(targetKey) {
+					restart(&syncStatus, false)
+				}
+				collect(&syncStatus)
+				result.statuses = append(result.statuses, syncStatus)
+				continue
+			}
+
 			// Create a copy of the target ConfigMap for updates
 			updatedConfigMap := targetConfigMap.DeepCopy()
 
@@ -321,12 +908,20 @@ func (r *ConfigMapSyncerReconciler) syncConfigMaps(
 				masterConfigMap.Name,
 			)
 
-			// Apply merge strategy
-			mergeStrategy := configMapSyncer.Spec.MergeStrategy
-			if mergeStrategy == "" {
-				mergeStrategy = MergeStrategyMerge
+			// Stamp the content hash and the master revision it was computed from
+			if updatedConfigMap.Annotations == nil {
+				updatedConfigMap.Annotations = make(map[string]string)
+			}
+			updatedConfigMap.Annotations[ContentHashAnnotation] = contentHash
+			updatedConfigMap.Annotations[SourceResourceVersionAnnotation] = masterConfigMap.ResourceVersion
+			if !exists {
+				updatedConfigMap.Annotations[CreatedByControllerAnnotation] = "true"
+			}
+			if immutable {
+				updatedConfigMap.Immutable = ptr.To(true)
 			}
 
+			// Apply merge strategy
 			switch mergeStrategy {
 			case MergeStrategyReplace:
 				// Replace all data with master ConfigMap data
@@ -338,7 +933,7 @@ func (r *ConfigMapSyncerReconciler) syncConfigMaps(
 				for k, v := range masterConfigMap.BinaryData {
 					updatedConfigMap.BinaryData[k] = v
 				}
-			case MergeStrategyMerge:
+			default:
 				// Merge data with master ConfigMap data
 				if updatedConfigMap.Data == nil {
 					updatedConfigMap.Data = make(map[string]string)
@@ -352,127 +947,98 @@ func (r *ConfigMapSyncerReconciler) syncConfigMaps(
 				for k, v := range masterConfigMap.BinaryData {
 					updatedConfigMap.BinaryData[k] = v
 				}
-			default:
-				logger.Info(
-					"Unknown merge strategy, using Merge",
-					"strategy",
-					mergeStrategy,
-				)
-				if updatedConfigMap.Data == nil {
-					updatedConfigMap.Data = make(map[string]string)
-				}
-				for k, v := range masterConfigMap.Data {
-					updatedConfigMap.Data[k] = v
-				}
-				if updatedConfigMap.BinaryData == nil {
-					updatedConfigMap.BinaryData = make(map[string][]byte)
-				}
-				for k, v := range masterConfigMap.BinaryData {
-					updatedConfigMap.BinaryData[k] = v
+			}
+
+			// A dry run only plans the write, the API server still validates and admits it
+			if cluster.dryRun {
+				change := planWrite(ctx, cluster, &targetConfigMap, updatedConfigMap, exists, kind, secretType, showValues)
+				syncStatus.Status = SyncStatusSynced
+				if change.Rejected {
+					syncStatus.Status = SyncStatusFailed
+					syncStatus.Message = change.Message
 				}
+				result.plan = append(result.plan, change)
+				result.statuses = append(result.statuses, syncStatus)
+				continue
 			}
 
-			// Check if the ConfigMap exists
-			existingConfigMap := &corev1.ConfigMap{}
-			err := r.Get(
-				ctx,
-				types.NamespacedName{
-					Name:      updatedConfigMap.Name,
-					Namespace: updatedConfigMap.Namespace,
-				},
-				existingConfigMap,
-			)
-			if err != nil {
-				if errors.IsNotFound(err) {
-					// ConfigMap doesn't exist, create it
-					if err := r.Create(ctx, updatedConfigMap); err != nil {
-						logger.Error(
-							err,
-							"Failed to create ConfigMap",
-							"namespace",
-							updatedConfigMap.Namespace,
-							"name",
-							updatedConfigMap.Name,
-						)
-						syncStatus.Status = SyncStatusFailed
-						syncStatus.Message = fmt.Sprintf(
-							"Failed to create ConfigMap: %v",
-							err,
-						)
-					} else {
-						logger.Info("Created ConfigMap", "namespace", updatedConfigMap.Namespace, "name", updatedConfigMap.Name)
-						syncStatus.Status = SyncStatusSynced
-						syncStatus.LastSyncTime = &metav1.Time{Time: time.Now()}
-					}
-				} else {
-					logger.Error(err, "Failed to get ConfigMap", "namespace", updatedConfigMap.Namespace, "name", updatedConfigMap.Name)
+			if !exists {
+				// Target doesn't exist, create it
+				if err := writeTarget(ctx, cluster.client, updatedConfigMap, kind, secretType, true); err != nil {
+					logger.Error(err, "Failed to create target", "kind", kind, "namespace", updatedConfigMap.Namespace, "name", updatedConfigMap.Name)
 					syncStatus.Status = SyncStatusFailed
-					syncStatus.Message = fmt.Sprintf("Failed to get ConfigMap: %v", err)
+					syncStatus.Message = fmt.Sprintf("Failed to create %s: %v", kind, err)
+				} else {
+					logger.Info("Created target", "kind", kind, "namespace", updatedConfigMap.Namespace, "name", updatedConfigMap.Name)
+					result.updated++
+					syncStatus.Status = SyncStatusSynced
+					syncStatus.LastSyncTime = &metav1.Time{Time: time.Now()}
+					restart(&syncStatus, true)
+					collect(&syncStatus)
 				}
 			} else {
-				// ConfigMap exists, check if it needs to be updated
-				if !reflect.DeepEqual(existingConfigMap.Data, updatedConfigMap.Data) || !reflect.DeepEqual(existingConfigMap.BinaryData, updatedConfigMap.BinaryData) {
-					// Update the ConfigMap
-					existingConfigMap.Data = updatedConfigMap.Data
-					existingConfigMap.BinaryData = updatedConfigMap.BinaryData
-					existingConfigMap.Labels = updatedConfigMap.Labels
-					if err := r.Update(ctx, existingConfigMap); err != nil {
-						logger.Error(err, "Failed to update ConfigMap", "namespace", existingConfigMap.Namespace, "name", existingConfigMap.Name)
-						syncStatus.Status = SyncStatusFailed
-						syncStatus.Message = fmt.Sprintf("Failed to update ConfigMap: %v", err)
-					} else {
-						logger.Info("Updated ConfigMap", "namespace", existingConfigMap.Namespace, "name", existingConfigMap.Name)
-						syncStatus.Status = SyncStatusSynced
-						syncStatus.LastSyncTime = &metav1.Time{Time: time.Now()}
-					}
+				// Target exists but was written from different content, update it
+				err := writeTarget(ctx, cluster.client, updatedConfigMap, kind, secretType, false)
+				if kind == KindSecret && secretTypeChanged(err) {
+					logger.Info("Recreating target whose Secret type changed", "namespace", updatedConfigMap.Namespace,
+						"name", updatedConfigMap.Name, "type", secretType)
+					err = replaceSecret(ctx, cluster.client, updatedConfigMap, secretType)
+				}
+				if err != nil {
+					logger.Error(err, "Failed to update target", "kind", kind, "namespace", updatedConfigMap.Namespace, "name", updatedConfigMap.Name)
+					syncStatus.Status = SyncStatusFailed
+					syncStatus.Message = fmt.Sprintf("Failed to update %s: %v", kind, err)
 				} else {
-					// ConfigMap is already in sync
-					logger.Info("ConfigMap is already in sync", "namespace", existingConfigMap.Namespace, "name", existingConfigMap.Name)
+					logger.Info("Updated target", "kind", kind, "namespace", updatedConfigMap.Namespace, "name", updatedConfigMap.Name)
+					result.updated++
 					syncStatus.Status = SyncStatusSynced
 					syncStatus.LastSyncTime = &metav1.Time{Time: time.Now()}
+					restart(&syncStatus, true)
+					collect(&syncStatus)
 				}
 			}
 
-			syncStatuses = append(syncStatuses, syncStatus)
+			result.statuses = append(result.statuses, syncStatus)
 		}
 	}
 
-	return syncStatuses, nil
+	return result, nil
 }
 
-// setCondition sets a condition on the ConfigMapSyncer status
+// setCondition sets a condition on the ConfigMapSyncer status. Reason, message
+// and observed generation are always updated, the transition time only
+// changes when the status of the condition does.
 func (r *ConfigMapSyncerReconciler) setCondition(
-	configMapSyncer *syncv1alpha1.ConfigMapSyncer,
+	configMapSyncer syncv1alpha1.Syncer,
 	condition metav1.Condition,
 ) {
-	// Set the transition time if it's not already set
-	if condition.LastTransitionTime.IsZero() {
-		condition.LastTransitionTime = metav1.NewTime(time.Now())
-	}
-
-	// Find and update existing condition or append new condition
-	for i, c := range configMapSyncer.Status.Conditions {
-		if c.Type == condition.Type {
-			// Only update if the status has changed
-			if c.Status != condition.Status {
-				configMapSyncer.Status.Conditions[i] = condition
-			}
-			return
-		}
-	}
-
-	// Condition doesn't exist, append it
-	configMapSyncer.Status.Conditions = append(
-		configMapSyncer.Status.Conditions,
-		condition,
-	)
+	condition.ObservedGeneration = configMapSyncer.GetGeneration()
+	meta.SetStatusCondition(&configMapSyncer.GetStatus().Conditions, condition)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ConfigMapSyncerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	cfg := r.Config
+	if cfg.IsZero() {
+		cfg = config.Default()
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	r.Config = cfg
+	if r.Clusters == nil {
+		r.Clusters = clusters.NewRegistry(mgr.GetScheme())
+	}
+	r.remoteMasters = newRemoteMasterWatches()
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&syncv1alpha1.ConfigMapSyncer{}).
 		Owns(&corev1.ConfigMap{}).
+		WatchesRawSource(r.remoteMasters.source()).
 		Named("configmapsyncer").
+		WithOptions(crcontroller.Options{
+			MaxConcurrentReconciles: cfg.MaxConcurrentReconciles,
+			RateLimiter:             newRateLimiter(cfg),
+		}).
 		Complete(r)
 }
@@ -18,11 +18,18 @@ package controller
 
 import (
 	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -81,4 +88,423 @@ var _ = Describe("ConfigMapSyncer Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When the spec is invalid", func() {
+		const resourceName = "invalid-selector"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the master ConfigMap")
+			createMaster(ctx, resourceName, map[string]string{"key": "value"})
+
+			By("creating a ConfigMapSyncer with a malformed target selector")
+			resource := &syncv1alpha1.ConfigMapSyncer{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: syncv1alpha1.ConfigMapSyncerSpec{
+					MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: resourceName, Namespace: "default"},
+					TargetNamespaces: []string{"kube-public"},
+					TargetSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: metav1.LabelSelectorOpIn},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteSyncer(ctx, &syncv1alpha1.ConfigMapSyncer{}, typeNamespacedName)
+		})
+
+		It("should return a terminal error and report InvalidSpec", func() {
+			controllerReconciler := &ConfigMapSyncerReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			By("adding the finalizer on the first pass")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("rejecting the selector on the second pass")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+			Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+			Expect(IsValidationError(err)).To(BeTrue())
+
+			resource := &syncv1alpha1.ConfigMapSyncer{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			ready := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Reason).To(Equal(ConditionReasonInvalidSpec))
+		})
+	})
+})
+
+var _ = Describe("Pruning targets", func() {
+	const (
+		resourceName = "prune-syncer"
+		masterName   = "prune-master"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+	namespaces := []string{"prune-app1", "prune-app2"}
+
+	BeforeEach(func() {
+		for _, ns := range namespaces {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        ns,
+				Annotations: map[string]string{AcceptFromAnnotation: "default"},
+			}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		}
+		createMaster(ctx, masterName, map[string]string{"key": "value"})
+
+		resource := &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: namespaces,
+				PrunePolicy:      PrunePolicyDelete,
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ConfigMapSyncer{}, typeNamespacedName)
+	})
+
+	It("should delete targets in namespaces that are no longer selected", func() {
+		controllerReconciler := &ConfigMapSyncerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+
+		By("syncing to both namespaces")
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		for _, ns := range namespaces {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: ns}, &corev1.ConfigMap{})).To(Succeed())
+		}
+
+		By("dropping the second namespace from the spec")
+		resource := &syncv1alpha1.ConfigMapSyncer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		resource.Spec.TargetNamespaces = namespaces[:1]
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: namespaces[1]}, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.TargetsTotal).To(Equal(int32(1)))
+		Expect(resource.Status.SyncStatuses).To(ContainElement(SatisfyAll(
+			HaveField("Namespace", namespaces[1]),
+			HaveField("Status", SyncStatusPruned),
+		)))
+	})
+
+	It("should only release targets it adopted", func() {
+		controllerReconciler := &ConfigMapSyncerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		adoptedKey := types.NamespacedName{Name: masterName, Namespace: namespaces[1]}
+		adopted := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: adoptedKey.Name, Namespace: adoptedKey.Namespace},
+			Data:       map[string]string{"own": "value"},
+		}
+		Expect(k8sClient.Create(ctx, adopted)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, adopted))).To(Succeed())
+		})
+
+		for range 2 {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Get(ctx, adoptedKey, adopted)).To(Succeed())
+		Expect(adopted.Labels).To(HaveKey(SourceConfigMapLabel))
+		Expect(adopted.Annotations).NotTo(HaveKey(CreatedByControllerAnnotation))
+
+		resource := &syncv1alpha1.ConfigMapSyncer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		resource.Spec.TargetNamespaces = namespaces[:1]
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, adoptedKey, adopted)).To(Succeed())
+		Expect(adopted.Labels).NotTo(HaveKey(SourceConfigMapLabel))
+		Expect(adopted.Data).To(HaveKeyWithValue("own", "value"))
+	})
+})
+
+var _ = Describe("inScope", func() {
+	configMapSyncer := &syncv1alpha1.ConfigMapSyncer{
+		Spec: syncv1alpha1.ConfigMapSyncerSpec{
+			MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: "app-config", Namespace: "default"},
+			TargetNamespaces: []string{"app1"},
+		},
+	}
+
+	It("should keep targets that are still selected", func() {
+		Expect(inScope(configMapSyncer, syncv1alpha1.SyncStatus{ConfigMapName: "app-config", Namespace: "app1"})).To(BeTrue())
+	})
+
+	It("should drop targets in removed namespaces or with an old name", func() {
+		Expect(inScope(configMapSyncer, syncv1alpha1.SyncStatus{ConfigMapName: "app-config", Namespace: "app2"})).To(BeFalse())
+		Expect(inScope(configMapSyncer, syncv1alpha1.SyncStatus{ConfigMapName: "old-name", Namespace: "app1"})).To(BeFalse())
+	})
+})
+
+var _ = Describe("Reconcile errors", func() {
+	It("should not retry validation errors", func() {
+		err := reconcileError(newValidationError("spec.targetSelector", goerrors.New("bad selector")))
+		Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+	})
+
+	It("should retry transient errors with backoff", func() {
+		err := reconcileError(newTransientError("list namespaces", goerrors.New("connection refused")))
+		Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeFalse())
+		Expect(IsValidationError(err)).To(BeFalse())
+	})
+})
+
+var _ = Describe("computeContentHash", func() {
+	master := &corev1.ConfigMap{
+		Data:       map[string]string{"a": "1", "b": "2"},
+		BinaryData: map[string][]byte{"bin": []byte{0x1}},
+	}
+	syncer := func(spec syncv1alpha1.ConfigMapSyncerSpec) syncv1alpha1.Syncer {
+		return &syncv1alpha1.ConfigMapSyncer{Spec: spec}
+	}
+	merge := syncer(syncv1alpha1.ConfigMapSyncerSpec{})
+
+	It("should be stable for the same content", func() {
+		Expect(computeContentHash(master, merge)).To(Equal(computeContentHash(master.DeepCopy(), merge)))
+	})
+
+	It("should change when the data, the merge strategy or the target kind changes", func() {
+		changed := master.DeepCopy()
+		changed.Data["b"] = "3"
+		Expect(computeContentHash(changed, merge)).NotTo(Equal(computeContentHash(master, merge)))
+		Expect(computeContentHash(master, syncer(syncv1alpha1.ConfigMapSyncerSpec{MergeStrategy: MergeStrategyReplace}))).
+			NotTo(Equal(computeContentHash(master, merge)))
+
+		secret := computeContentHash(master, syncer(syncv1alpha1.ConfigMapSyncerSpec{TargetKind: KindSecret}))
+		Expect(secret).NotTo(Equal(computeContentHash(master, merge)))
+		Expect(computeContentHash(master, syncer(syncv1alpha1.ConfigMapSyncerSpec{
+			TargetKind:       KindSecret,
+			TargetSecretType: string(corev1.SecretTypeDockerConfigJson),
+		}))).NotTo(Equal(secret))
+	})
+
+	It("should not confuse keys and values", func() {
+		left := &corev1.ConfigMap{Data: map[string]string{"ab": "c"}}
+		right := &corev1.ConfigMap{Data: map[string]string{"a": "bc"}}
+		Expect(computeContentHash(left, merge)).NotTo(Equal(computeContentHash(right, merge)))
+	})
+})
+
+var _ = Describe("holdsContent", func() {
+	master := &corev1.ConfigMap{
+		Data:       map[string]string{"a": "1"},
+		BinaryData: map[string][]byte{"bin": []byte{0xff}, "text": []byte("plain")},
+	}
+	merge := &syncv1alpha1.ConfigMapSyncer{}
+	replace := &syncv1alpha1.ConfigMapSyncer{Spec: syncv1alpha1.ConfigMapSyncerSpec{MergeStrategy: MergeStrategyReplace}}
+
+	It("should ignore keys of the target that the master does not have when merging", func() {
+		target := master.DeepCopy()
+		target.Data["own"] = "value"
+		Expect(holdsContent(target, master, merge)).To(BeTrue())
+		Expect(holdsContent(target, master, replace)).To(BeFalse())
+	})
+
+	It("should detect edited or removed values", func() {
+		edited := master.DeepCopy()
+		edited.Data["a"] = "2"
+		Expect(holdsContent(edited, master, merge)).To(BeFalse())
+		removed := master.DeepCopy()
+		delete(removed.BinaryData, "bin")
+		Expect(holdsContent(removed, master, merge)).To(BeFalse())
+	})
+
+	It("should compare Secret targets whose text values are read back as data", func() {
+		secret := &syncv1alpha1.ConfigMapSyncer{Spec: syncv1alpha1.ConfigMapSyncerSpec{TargetKind: KindSecret}}
+		target := configMapFromSecret(secretFromConfigMap(master, corev1.SecretTypeOpaque))
+		Expect(target.Data).To(HaveKey("text"))
+		Expect(holdsContent(target, master, secret)).To(BeTrue())
+	})
+})
+
+var _ = Describe("CacheByObject", func() {
+	It("should only cache ConfigMaps written by the controller", func() {
+		byObject := CacheByObject()
+		Expect(byObject).To(HaveLen(1))
+		for obj, opts := range byObject {
+			Expect(obj).To(BeAssignableToTypeOf(&corev1.ConfigMap{}))
+			Expect(opts.Label.Matches(labels.Set{SourceConfigMapLabel: "default.app-config"})).To(BeTrue())
+			Expect(opts.Label.Matches(labels.Set{"app": "unrelated"})).To(BeFalse())
+		}
+	})
+})
+
+var _ = Describe("statusEqualIgnoringTimestamps", func() {
+	status := &syncv1alpha1.ConfigMapSyncerStatus{
+		Conditions: []metav1.Condition{{
+			Type:               ConditionTypeReady,
+			Status:             metav1.ConditionTrue,
+			Reason:             ConditionReasonSyncSuccess,
+			LastTransitionTime: metav1.Now(),
+		}},
+		SyncStatuses: []syncv1alpha1.SyncStatus{{
+			ConfigMapName: "app-config",
+			Namespace:     "app1",
+			Status:        SyncStatusSynced,
+			LastSyncTime:  &metav1.Time{Time: time.Now()},
+		}},
+		LastSyncTime: &metav1.Time{Time: time.Now()},
+	}
+
+	It("should ignore sync and transition timestamps", func() {
+		later := status.DeepCopy()
+		later.LastSyncTime = &metav1.Time{Time: time.Now().Add(time.Minute)}
+		later.SyncStatuses[0].LastSyncTime = &metav1.Time{Time: time.Now().Add(time.Minute)}
+		later.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(time.Minute))
+		Expect(statusEqualIgnoringTimestamps(status, later)).To(BeTrue())
+	})
+
+	It("should detect a changed target status", func() {
+		failed := status.DeepCopy()
+		failed.SyncStatuses[0].Status = SyncStatusFailed
+		Expect(statusEqualIgnoringTimestamps(status, failed)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Sync conditions", func() {
+	var (
+		reconciler      *ConfigMapSyncerReconciler
+		configMapSyncer *syncv1alpha1.ConfigMapSyncer
+	)
+
+	BeforeEach(func() {
+		reconciler = &ConfigMapSyncerReconciler{}
+		configMapSyncer = &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: "conditions", Namespace: "default", Generation: 2},
+		}
+	})
+
+	It("should not report Ready when some targets failed", func() {
+		reconciler.setSyncConditions(configMapSyncer, &syncResult{
+			statuses: []syncv1alpha1.SyncStatus{
+				{ConfigMapName: "app-config", Namespace: "app1", Status: SyncStatusSynced},
+				{ConfigMapName: "app-config", Namespace: "app2", Status: SyncStatusFailed},
+				{ConfigMapName: "app-config", Namespace: "app3", Status: SyncStatusSynced},
+			},
+			updated: 2,
+		})
+
+		ready := meta.FindStatusCondition(configMapSyncer.Status.Conditions, ConditionTypeReady)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Message).To(Equal("1/3 targets failed"))
+		Expect(ready.ObservedGeneration).To(Equal(int64(2)))
+		Expect(meta.IsStatusConditionTrue(configMapSyncer.Status.Conditions, ConditionTypeDegraded)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(configMapSyncer.Status.Conditions, ConditionTypeProgressing)).To(BeTrue())
+	})
+
+	It("should update reason and message when the status does not change", func() {
+		reconciler.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonMasterConfigMapNotFound,
+			Message: "Master ConfigMap default/app-config not found",
+		})
+		configMapSyncer.Generation = 3
+		reconciler.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonTargetsFailed,
+			Message: "2/5 targets failed",
+		})
+
+		ready := meta.FindStatusCondition(configMapSyncer.Status.Conditions, ConditionTypeReady)
+		Expect(ready.Reason).To(Equal(ConditionReasonTargetsFailed))
+		Expect(ready.Message).To(Equal("2/5 targets failed"))
+		Expect(ready.ObservedGeneration).To(Equal(int64(3)))
+	})
+})
+
+var _ = Describe("Status reporting", func() {
+	statusesFor := func(n int) []syncv1alpha1.SyncStatus {
+		statuses := make([]syncv1alpha1.SyncStatus, n)
+		for i := range statuses {
+			statuses[i] = syncv1alpha1.SyncStatus{
+				ConfigMapName: "app-config",
+				Namespace:     fmt.Sprintf("app%d", i),
+				Status:        SyncStatusSynced,
+			}
+		}
+		return statuses
+	}
+
+	It("should switch to reports above the threshold in Auto mode", func() {
+		configMapSyncer := &syncv1alpha1.ConfigMapSyncer{}
+		Expect(useReports(configMapSyncer, defaultReportThreshold)).To(BeFalse())
+		Expect(useReports(configMapSyncer, defaultReportThreshold+1)).To(BeTrue())
+
+		configMapSyncer.Spec.StatusReporting = &syncv1alpha1.StatusReporting{Mode: StatusReportingAuto, Threshold: 5}
+		Expect(useReports(configMapSyncer, 5)).To(BeFalse())
+		Expect(useReports(configMapSyncer, 6)).To(BeTrue())
+	})
+
+	It("should honor an explicit mode", func() {
+		configMapSyncer := &syncv1alpha1.ConfigMapSyncer{}
+		configMapSyncer.Spec.StatusReporting = &syncv1alpha1.StatusReporting{Mode: StatusReportingInline}
+		Expect(useReports(configMapSyncer, 5000)).To(BeFalse())
+
+		configMapSyncer.Spec.StatusReporting = &syncv1alpha1.StatusReporting{Mode: StatusReportingReport}
+		Expect(useReports(configMapSyncer, 1)).To(BeTrue())
+	})
+
+	It("should keep the statuses inline for small syncers", func() {
+		configMapSyncer := &syncv1alpha1.ConfigMapSyncer{}
+		reconciler := &ConfigMapSyncerReconciler{}
+
+		Expect(reconciler.recordSyncStatuses(context.Background(), configMapSyncer, statusesFor(3))).To(Succeed())
+		Expect(configMapSyncer.Status.SyncStatuses).To(HaveLen(3))
+		Expect(configMapSyncer.Status.RecentFailures).To(BeEmpty())
+		Expect(configMapSyncer.Status.Reports).To(BeEmpty())
+	})
+
+	It("should report new failures first and count the omitted ones", func() {
+		statuses := statusesFor(4)
+		for i := range statuses {
+			statuses[i].Status = SyncStatusFailed
+		}
+		previous := []syncv1alpha1.SyncStatus{statuses[2], statuses[0]}
+
+		failures, omitted := recentFailures(previous, statuses, 3)
+		Expect(omitted).To(Equal(1))
+		Expect(failures).To(HaveExactElements(
+			HaveField("Namespace", "app1"),
+			HaveField("Namespace", "app3"),
+			HaveField("Namespace", "app2"),
+		))
+	})
 })
@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// KeyAdded is the change of a key that did not exist before
+	KeyAdded = "Added"
+
+	// KeyRemoved is the change of a key that no longer exists
+	KeyRemoved = "Removed"
+
+	// KeyChanged is the change of a key whose value changed
+	KeyChanged = "Changed"
+
+	// maxDiffValueLength is the number of characters of a value shown in a diff
+	maxDiffValueLength = 256
+)
+
+// diffContent returns the changes from the data of old to the data of new,
+// sorted by key. Either may be nil. Values are only shown when showValues is
+// set and never for binary data.
+func diffContent(old, new *corev1.ConfigMap, showValues bool) []syncv1alpha1.KeyDiff {
+	if old == nil {
+		old = &corev1.ConfigMap{}
+	}
+	if new == nil {
+		new = &corev1.ConfigMap{}
+	}
+
+	var diff []syncv1alpha1.KeyDiff
+	value := func(v string) string {
+		if !showValues {
+			return ""
+		}
+		return shorten(v)
+	}
+	for key, newValue := range new.Data {
+		oldValue, ok := old.Data[key]
+		switch {
+		case !ok:
+			diff = append(diff, syncv1alpha1.KeyDiff{Key: key, Change: KeyAdded, New: value(newValue)})
+		case oldValue != newValue:
+			diff = append(diff, syncv1alpha1.KeyDiff{Key: key, Change: KeyChanged, Old: value(oldValue), New: value(newValue)})
+		}
+	}
+	for key, oldValue := range old.Data {
+		if _, ok := new.Data[key]; !ok {
+			diff = append(diff, syncv1alpha1.KeyDiff{Key: key, Change: KeyRemoved, Old: value(oldValue)})
+		}
+	}
+	for key, newValue := range new.BinaryData {
+		oldValue, ok := old.BinaryData[key]
+		switch {
+		case !ok:
+			diff = append(diff, syncv1alpha1.KeyDiff{Key: key, Change: KeyAdded})
+		case !bytes.Equal(oldValue, newValue):
+			diff = append(diff, syncv1alpha1.KeyDiff{Key: key, Change: KeyChanged})
+		}
+	}
+	for key := range old.BinaryData {
+		if _, ok := new.BinaryData[key]; !ok {
+			diff = append(diff, syncv1alpha1.KeyDiff{Key: key, Change: KeyRemoved})
+		}
+	}
+
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].Key < diff[j].Key
+	})
+	return diff
+}
+
+// shorten cuts a value down to the length shown in a diff
+func shorten(value string) string {
+	runes := []rune(value)
+	if len(runes) <= maxDiffValueLength {
+		return value
+	}
+	return string(runes[:maxDiffValueLength]) + "..."
+}
+
+// showsValues reports whether diffs of the syncer may show values. Secrets,
+// as master or target, are never shown.
+func showsValues(configMapSyncer syncv1alpha1.Syncer) bool {
+	return configMapSyncer.GetSpec().MasterConfigMap.Kind != KindSecret && targetKind(configMapSyncer) != KindSecret
+}
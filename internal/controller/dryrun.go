@@ -0,0 +1,260 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// PlannedActionCreate is the planned change of a target that would be created
+	PlannedActionCreate = "Create"
+
+	// PlannedActionUpdate is the planned change of a target that would be updated
+	PlannedActionUpdate = "Update"
+
+	// PlannedActionPrune is the planned change of a target that would be deleted or released
+	PlannedActionPrune = "Prune"
+
+	// PlannedActionSkip is the planned change of a target that would be left as it is
+	PlannedActionSkip = "Skip"
+
+	// ConditionTypeDryRun is the type for the condition reporting the plan of a dry run
+	ConditionTypeDryRun = "DryRun"
+
+	// ConditionReasonChangesPlanned is the reason when a dry run planned changes to targets
+	ConditionReasonChangesPlanned = "ChangesPlanned"
+
+	// ConditionReasonChangesRejected is the reason when the API server rejected some of the planned writes
+	ConditionReasonChangesRejected = "ChangesRejected"
+
+	// EventReasonDryRunPlanned is the event reason when a dry run planned different changes than before
+	EventReasonDryRunPlanned = "DryRunPlanned"
+
+	// EventReasonDryRunRejected is the event reason when the API server rejected a planned write
+	EventReasonDryRunRejected = "DryRunRejected"
+
+	// maxPlannedChanges is the number of targets listed in status.dryRun.changes
+	maxPlannedChanges = 500
+)
+
+// dryRun reports whether the syncer only plans its changes
+func (r *ConfigMapSyncerReconciler) dryRun(configMapSyncer syncv1alpha1.Syncer) bool {
+	return configMapSyncer.GetSpec().DryRun || r.Config.DryRun
+}
+
+// asDryRun returns a copy of the cluster whose writes are server-side dry runs
+func (c *targetCluster) asDryRun() *targetCluster {
+	dryRun := *c
+	dryRun.client = client.NewDryRunClient(c.client)
+	dryRun.dryRun = true
+	return &dryRun
+}
+
+// planWrite sends the create or update of a target as a dry run and returns
+// the planned change with the keys it would change
+func planWrite(
+	ctx context.Context,
+	cluster *targetCluster,
+	current, desired *corev1.ConfigMap,
+	exists bool,
+	kind string,
+	secretType corev1.SecretType,
+	showValues bool,
+) syncv1alpha1.PlannedChange {
+	change := syncv1alpha1.PlannedChange{
+		Cluster:   cluster.name,
+		Kind:      statusKind(kind),
+		Namespace: desired.Namespace,
+		Name:      desired.Name,
+		Action:    PlannedActionCreate,
+		Diff:      diffContent(current, desired, showValues),
+	}
+	if exists {
+		change.Action = PlannedActionUpdate
+	}
+	err := writeTarget(ctx, cluster.client, desired, kind, secretType, !exists)
+	if kind == KindSecret && secretTypeChanged(err) {
+		// The Secret would be deleted and created again with the new type
+		change.Message = fmt.Sprintf("The Secret would be recreated with type %s", secretType)
+		err = replaceableSecret(desired, secretType)
+	}
+	if err != nil {
+		log.FromContext(ctx).Info("Dry run of target write was rejected", "cluster", cluster.displayName(),
+			"kind", kind, "namespace", desired.Namespace, "name", desired.Name, "error", err.Error())
+		change.Rejected = true
+		change.Message = err.Error()
+	}
+	return change
+}
+
+// plannedPrunes returns the planned changes for the outcomes of a dry-run prune
+func plannedPrunes(pruned []syncv1alpha1.SyncStatus) []syncv1alpha1.PlannedChange {
+	changes := make([]syncv1alpha1.PlannedChange, 0, len(pruned))
+	for _, status := range pruned {
+		changes = append(changes, syncv1alpha1.PlannedChange{
+			Cluster:   status.Cluster,
+			Kind:      status.Kind,
+			Namespace: status.Namespace,
+			Name:      status.ConfigMapName,
+			Action:    PlannedActionPrune,
+			Rejected:  status.Status == SyncStatusFailed,
+			Message:   status.Message,
+		})
+	}
+	return changes
+}
+
+// newDryRunStatus sums up the planned changes of a sync. Targets without a
+// planned write or prune are skipped, their sync status tells why.
+func newDryRunStatus(result *syncResult, now metav1.Time) *syncv1alpha1.DryRunStatus {
+	status := &syncv1alpha1.DryRunStatus{Time: now}
+	planned := make(map[targetKey]bool, len(result.plan))
+	changes := make([]syncv1alpha1.PlannedChange, 0, len(result.statuses))
+	for _, change := range result.plan {
+		planned[plannedTargetKey(change)] = true
+		switch change.Action {
+		case PlannedActionCreate:
+			status.Create++
+		case PlannedActionUpdate:
+			status.Update++
+		case PlannedActionPrune:
+			status.Prune++
+		}
+		if change.Rejected {
+			status.Rejected++
+		}
+		changes = append(changes, change)
+	}
+
+	for _, syncStatus := range result.statuses {
+		change := syncv1alpha1.PlannedChange{
+			Cluster:   syncStatus.Cluster,
+			Kind:      syncStatus.Kind,
+			Namespace: syncStatus.Namespace,
+			Name:      syncStatus.ConfigMapName,
+			Action:    PlannedActionSkip,
+			Message:   syncStatus.Message,
+		}
+		if planned[plannedTargetKey(change)] {
+			continue
+		}
+		if change.Message == "" && syncStatus.Status == SyncStatusSynced {
+			change.Message = "Already in sync"
+		}
+		status.Skip++
+		changes = append(changes, change)
+	}
+
+	if len(changes) > maxPlannedChanges {
+		changes = changes[:maxPlannedChanges]
+		status.Truncated = true
+	}
+	status.Changes = changes
+	return status
+}
+
+// plannedTargetKey returns the key of the target of a planned change
+func plannedTargetKey(change syncv1alpha1.PlannedChange) targetKey {
+	return targetKeyOf(syncv1alpha1.SyncStatus{
+		Cluster:       change.Cluster,
+		Kind:          change.Kind,
+		Namespace:     change.Namespace,
+		ConfigMapName: change.Name,
+	})
+}
+
+// summary describes the counts of a dry run
+func summary(status *syncv1alpha1.DryRunStatus) string {
+	message := fmt.Sprintf("%d to create, %d to update, %d to prune, %d to skip",
+		status.Create, status.Update, status.Prune, status.Skip)
+	if status.Rejected > 0 {
+		message += fmt.Sprintf(", %d rejected", status.Rejected)
+	}
+	return message
+}
+
+// finishDryRun records the plan of a dry run in the status. The rest of the
+// status is left as the last sync that wrote the targets reported it. Events
+// are only emitted when the plan changed.
+func (r *ConfigMapSyncerReconciler) finishDryRun(
+	ctx context.Context,
+	original syncv1alpha1.Syncer,
+	configMapSyncer syncv1alpha1.Syncer,
+	result *syncResult,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	status := newDryRunStatus(result, metav1.NewTime(r.now()))
+	previous := configMapSyncer.GetStatus().DryRun
+	configMapSyncer.GetStatus().DryRun = status
+	configMapSyncer.GetStatus().ObservedGeneration = configMapSyncer.GetGeneration()
+
+	message := summary(status)
+	reason := ConditionReasonUpToDate
+	switch {
+	case status.Rejected > 0:
+		reason = ConditionReasonChangesRejected
+	case status.Create+status.Update+status.Prune > 0:
+		reason = ConditionReasonChangesPlanned
+	}
+	r.setCondition(configMapSyncer, metav1.Condition{
+		Type:    ConditionTypeDryRun,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	logger.Info("Planned dry run", "plan", message)
+
+	if previous == nil || summary(previous) != message {
+		r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonDryRunPlanned, "Dry run: %s", message)
+		for _, change := range status.Changes {
+			if !change.Rejected {
+				continue
+			}
+			kind := change.Kind
+			if kind == "" {
+				kind = KindConfigMap
+			}
+			r.event(configMapSyncer, corev1.EventTypeWarning, EventReasonDryRunRejected, "%s of %s %s was rejected: %s",
+				change.Action, kind, types.NamespacedName{Namespace: change.Namespace, Name: change.Name}, change.Message)
+		}
+	}
+
+	if err := r.patchStatus(ctx, original, configMapSyncer); err != nil {
+		logger.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: syncInterval(configMapSyncer)}, nil
+}
+
+// clearDryRun removes the plan of an earlier dry run once targets are written again
+func clearDryRun(configMapSyncer syncv1alpha1.Syncer) {
+	configMapSyncer.GetStatus().DryRun = nil
+	meta.RemoveStatusCondition(&configMapSyncer.GetStatus().Conditions, ConditionTypeDryRun)
+}
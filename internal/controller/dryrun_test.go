@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Dry run", func() {
+	const (
+		resourceName = "dryrun-syncer"
+		masterName   = "dryrun-config"
+		newNamespace = "dryrun-new"
+		oldNamespace = "dryrun-old"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+	masterKey := types.NamespacedName{Name: masterName, Namespace: "default"}
+	newKey := types.NamespacedName{Name: masterName, Namespace: newNamespace}
+	oldKey := types.NamespacedName{Name: masterName, Namespace: oldNamespace}
+
+	BeforeEach(func() {
+		for _, name := range []string{newNamespace, oldNamespace} {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		}
+		createMaster(ctx, masterName, map[string]string{"color": "blue", "size": "large"})
+		old := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: oldNamespace},
+			Data:       map[string]string{"color": "red", "shape": "round"},
+		}
+		Expect(k8sClient.Create(ctx, old)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+		for _, key := range []types.NamespacedName{masterKey, newKey, oldKey} {
+			target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, target))).To(Succeed())
+		}
+	})
+
+	It("should plan the changes without writing any target", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{newNamespace, oldNamespace},
+				DryRun:           true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		reconcileOnce(ctx, controllerReconciler, resource)
+		plan := resource.Status.DryRun
+		Expect(plan).NotTo(BeNil())
+		Expect(plan.Create).To(Equal(int32(1)))
+		Expect(plan.Update).To(Equal(int32(1)))
+		Expect(plan.Rejected).To(BeZero())
+		Expect(plan.Changes).To(ConsistOf(
+			syncv1alpha1.PlannedChange{
+				Namespace: newNamespace,
+				Name:      masterName,
+				Action:    PlannedActionCreate,
+				Diff: []syncv1alpha1.KeyDiff{
+					{Key: "color", Change: KeyAdded, New: "blue"},
+					{Key: "size", Change: KeyAdded, New: "large"},
+				},
+			},
+			syncv1alpha1.PlannedChange{
+				Namespace: oldNamespace,
+				Name:      masterName,
+				Action:    PlannedActionUpdate,
+				Diff: []syncv1alpha1.KeyDiff{
+					{Key: "color", Change: KeyChanged, Old: "red", New: "blue"},
+					{Key: "size", Change: KeyAdded, New: "large"},
+				},
+			},
+		))
+		Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionTypeDryRun)).To(BeTrue())
+		Expect(resource.Status.SyncStatuses).To(BeEmpty())
+		Expect(resource.Status.Revisions).To(BeEmpty())
+		revisions, err := controllerReconciler.listRevisions(ctx, resource)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(revisions).To(BeEmpty())
+
+		err = k8sClient.Get(ctx, newKey, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		old := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, oldKey, old)).To(Succeed())
+		Expect(old.Data).To(Equal(map[string]string{"color": "red", "shape": "round"}))
+
+		resource.Spec.DryRun = false
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(resource.Status.DryRun).To(BeNil())
+		Expect(meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeDryRun)).To(BeNil())
+		Expect(k8sClient.Get(ctx, oldKey, old)).To(Succeed())
+		Expect(old.Data).To(Equal(map[string]string{"color": "blue", "shape": "round", "size": "large"}))
+		Expect(k8sClient.Get(ctx, newKey, &corev1.ConfigMap{})).To(Succeed())
+	})
+})
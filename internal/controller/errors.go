@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ValidationError is returned when the ConfigMapSyncer spec cannot be acted on.
+// Retrying does not help until the spec is changed, so it is not requeued.
+type ValidationError struct {
+	// Field is the path of the offending spec field
+	Field string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// TransientError is returned when a call to the API server fails in a way
+// that is expected to resolve on its own, so it is retried with backoff.
+type TransientError struct {
+	// Op describes the operation that failed
+	Op  string
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", e.Op, e.Err)
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// newValidationError returns a ValidationError for the given spec field
+func newValidationError(field string, err error) error {
+	return &ValidationError{Field: field, Err: err}
+}
+
+// newTransientError returns a TransientError for the given operation
+func newTransientError(op string, err error) error {
+	return &TransientError{Op: op, Err: err}
+}
+
+// IsValidationError reports whether err is or wraps a ValidationError
+func IsValidationError(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
+
+// reconcileError converts an error into the value Reconcile returns. Validation
+// errors are terminal and skip the rate limiter; everything else is requeued
+// with backoff.
+func reconcileError(err error) error {
+	if IsValidationError(err) {
+		return reconcile.TerminalError(err)
+	}
+	return err
+}
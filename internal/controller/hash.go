@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+// computeContentHash returns a stable hash of the data the master ConfigMap
+// contributes to the targets of the syncer. The merge strategy, the target
+// kind and the Secret type are part of the hash so that switching any of them
+// rewrites every target.
+func computeContentHash(masterConfigMap *corev1.ConfigMap, configMapSyncer syncv1alpha1.Syncer) string {
+	h := sha256.New()
+	writeHashField(h, []byte(mergeStrategyOf(configMapSyncer)))
+	kind := targetKind(configMapSyncer)
+	writeHashField(h, []byte(kind))
+	if kind == KindSecret {
+		writeHashField(h, []byte(targetSecretType(configMapSyncer)))
+	}
+
+	keys := make([]string, 0, len(masterConfigMap.Data))
+	for k := range masterConfigMap.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	writeHashField(h, []byte("data"))
+	for _, k := range keys {
+		writeHashField(h, []byte(k))
+		writeHashField(h, []byte(masterConfigMap.Data[k]))
+	}
+
+	keys = keys[:0]
+	for k := range masterConfigMap.BinaryData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	writeHashField(h, []byte("binaryData"))
+	for _, k := range keys {
+		writeHashField(h, []byte(k))
+		writeHashField(h, masterConfigMap.BinaryData[k])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashField writes a length-prefixed field so that adjacent fields
+// cannot be shifted into each other to produce the same hash
+func writeHashField(h hash.Hash, field []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+	h.Write(length[:])
+	h.Write(field)
+}
+
+// holdsContent reports whether the data of a target read from the cache is
+// what syncing the master would write, so that manual edits of targets that
+// carry the current hash are still repaired. With the Merge strategy only the
+// keys of the master are compared.
+func holdsContent(target, masterConfigMap *corev1.ConfigMap, configMapSyncer syncv1alpha1.Syncer) bool {
+	expected := masterConfigMap
+	if targetKind(configMapSyncer) == KindSecret {
+		// A Secret does not keep data and binary data apart, its values are read
+		// back as data whenever they are valid UTF-8
+		expected = configMapFromSecret(secretFromConfigMap(masterConfigMap, ""))
+	}
+	actual := &corev1.ConfigMap{Data: target.Data, BinaryData: target.BinaryData}
+	if mergeStrategyOf(configMapSyncer) != MergeStrategyReplace {
+		actual = &corev1.ConfigMap{}
+		for k := range expected.Data {
+			if v, ok := target.Data[k]; ok {
+				if actual.Data == nil {
+					actual.Data = make(map[string]string)
+				}
+				actual.Data[k] = v
+			}
+		}
+		for k := range expected.BinaryData {
+			if v, ok := target.BinaryData[k]; ok {
+				if actual.BinaryData == nil {
+					actual.BinaryData = make(map[string][]byte)
+				}
+				actual.BinaryData[k] = v
+			}
+		}
+	}
+	return computeContentHash(actual, configMapSyncer) == computeContentHash(expected, configMapSyncer)
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// ImmutablePointerAnnotation publishes the name of the current version on the syncer
+	ImmutablePointerAnnotation = "configmapsyncer.conf-sync.com/current-target"
+
+	// ImmutablePointerAliasConfigMap publishes the name of the current version in an alias ConfigMap
+	ImmutablePointerAliasConfigMap = "AliasConfigMap"
+
+	// AliasNameKey is the key of the alias ConfigMap holding the name of the current version
+	AliasNameKey = "name"
+
+	// AliasAnnotation marks the alias ConfigMap of immutable targets
+	AliasAnnotation = "configmapsyncer.conf-sync.com/alias"
+
+	// EventReasonTargetVersionDeleted is the event reason when an old version of an immutable target was deleted
+	EventReasonTargetVersionDeleted = "TargetVersionDeleted"
+
+	// defaultKeepVersions is the number of versions kept when spec.immutableTargets.keep is not set
+	defaultKeepVersions = 3
+
+	// versionHashLength is the number of characters of the content hash in the name of a version
+	versionHashLength = 10
+)
+
+// versionedTargetName returns the name of the version of the target holding the content with the given hash
+func versionedTargetName(name, hash string) string {
+	return name + "-" + hash[:versionHashLength]
+}
+
+// isVersionOf reports whether the name is the name of a version of the target
+func isVersionOf(name, target string) bool {
+	return len(name) == len(target)+1+versionHashLength && strings.HasPrefix(name, target+"-")
+}
+
+// keepVersions returns the number of versions kept in every namespace
+func keepVersions(immutable *syncv1alpha1.ImmutableTargets) int {
+	if immutable.Keep > 0 {
+		return int(immutable.Keep)
+	}
+	return defaultKeepVersions
+}
+
+// collectVersions deletes the versions of the target in the namespace beyond
+// the number to keep, oldest first, and publishes the current version in the
+// alias ConfigMap if one is configured. The current version is always kept.
+// The versions found are added to the result, they are not pruned.
+func (r *ConfigMapSyncerReconciler) collectVersions(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	cluster *targetCluster,
+	authorizer *tenantAuthorizer,
+	masterConfigMap *corev1.ConfigMap,
+	kind string,
+	target string,
+	current types.NamespacedName,
+	result *syncResult,
+) error {
+	logger := log.FromContext(ctx).WithValues("cluster", cluster.displayName())
+	immutable := configMapSyncer.GetSpec().ImmutableTargets
+
+	// The alias takes the name of the plain target, which must not be pruned
+	alias := types.NamespacedName{Name: target, Namespace: current.Namespace}
+	if immutable.Pointer == ImmutablePointerAliasConfigMap {
+		result.versions[targetKey{cluster: cluster.name, kind: KindConfigMap, NamespacedName: alias}] = true
+	}
+
+	source := labels.SelectorFromSet(labels.Set{SourceConfigMapLabel: fmt.Sprintf("%s.%s", masterConfigMap.Namespace, masterConfigMap.Name)})
+	targets, err := listTargets(ctx, cluster.reader, current.Namespace, source, kind)
+	if err != nil {
+		return fmt.Errorf("list versions: %w", err)
+	}
+	var versions []corev1.ConfigMap
+	for _, version := range targets {
+		if isVersionOf(version.Name, target) && version.Name != current.Name {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[j].CreationTimestamp.Before(&versions[i].CreationTimestamp)
+	})
+
+	for i, version := range versions {
+		key := types.NamespacedName{Name: version.Name, Namespace: version.Namespace}
+		result.versions[targetKey{cluster: cluster.name, kind: kind, NamespacedName: key}] = true
+		if i+1 < keepVersions(immutable) {
+			continue
+		}
+		if cluster.dryRun {
+			result.plan = append(result.plan, syncv1alpha1.PlannedChange{
+				Cluster:   cluster.name,
+				Kind:      statusKind(kind),
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Action:    PlannedActionPrune,
+				Message:   "Old version beyond the kept ones",
+			})
+			continue
+		}
+
+		reason, err := authorizer.authorize(ctx, key, "delete")
+		if err != nil {
+			return fmt.Errorf("authorize deleting version %s: %w", version.Name, err)
+		}
+		if reason != "" {
+			return fmt.Errorf("delete version %s: %s", version.Name, reason)
+		}
+		obj := newTargetObject(kind)
+		obj.SetName(version.Name)
+		obj.SetNamespace(version.Namespace)
+		resourceVersion := version.ResourceVersion
+		if err := client.IgnoreNotFound(cluster.client.Delete(ctx, obj, client.Preconditions{ResourceVersion: &resourceVersion})); err != nil {
+			return fmt.Errorf("delete version %s: %w", version.Name, err)
+		}
+		logger.Info("Deleted old version of target", "kind", kind, "namespace", key.Namespace, "name", key.Name)
+		r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonTargetVersionDeleted,
+			"Deleted old version %s %s", kind, key)
+	}
+
+	if immutable.Pointer != ImmutablePointerAliasConfigMap || cluster.dryRun {
+		return nil
+	}
+	return r.writeAlias(ctx, cluster, authorizer, masterConfigMap, alias, current.Name)
+}
+
+// writeAlias creates or updates the alias ConfigMap pointing at the current
+// version. An existing ConfigMap is only updated if it is the alias of the
+// same master, ConfigMaps of others are never overwritten. A plain target of
+// the same master the controller created, left over from before the targets
+// were immutable, is deleted first so that none of its data is kept.
+func (r *ConfigMapSyncerReconciler) writeAlias(
+	ctx context.Context,
+	cluster *targetCluster,
+	authorizer *tenantAuthorizer,
+	masterConfigMap *corev1.ConfigMap,
+	key types.NamespacedName,
+	current string,
+) error {
+	alias := &corev1.ConfigMap{}
+	exists := true
+	if err := cluster.reader.Get(ctx, key, alias); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("get alias ConfigMap: %w", err)
+		}
+		exists = false
+		alias = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	}
+	source := fmt.Sprintf("%s.%s", masterConfigMap.Namespace, masterConfigMap.Name)
+	if exists && alias.Labels[SourceConfigMapLabel] != source {
+		return fmt.Errorf("ConfigMap %s already exists and is not the alias of master %s/%s",
+			key, masterConfigMap.Namespace, masterConfigMap.Name)
+	}
+	if exists && alias.Annotations[AliasAnnotation] != "true" {
+		if alias.Annotations[CreatedByControllerAnnotation] != "true" {
+			return fmt.Errorf("ConfigMap %s is an adopted target of master %s/%s and is not replaced by its alias",
+				key, masterConfigMap.Namespace, masterConfigMap.Name)
+		}
+		reason, err := authorizer.authorizeResource(ctx, "", "configmaps", KindConfigMap, key, "delete")
+		if err != nil {
+			return fmt.Errorf("authorize deleting target %s: %w", key, err)
+		}
+		if reason != "" {
+			return fmt.Errorf("delete target %s: %s", key, reason)
+		}
+		resourceVersion := alias.ResourceVersion
+		if err := client.IgnoreNotFound(cluster.client.Delete(ctx, alias, client.Preconditions{ResourceVersion: &resourceVersion})); err != nil {
+			return fmt.Errorf("delete target %s: %w", key, err)
+		}
+		exists = false
+		alias = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	}
+	if exists && alias.Data[AliasNameKey] == current {
+		return nil
+	}
+
+	verb := "update"
+	if !exists {
+		verb = "create"
+	}
+	reason, err := authorizer.authorizeResource(ctx, "", "configmaps", KindConfigMap, key, verb)
+	if err != nil {
+		return fmt.Errorf("authorize alias ConfigMap: %w", err)
+	}
+	if reason != "" {
+		return fmt.Errorf("write alias ConfigMap: %s", reason)
+	}
+
+	alias.Data = map[string]string{AliasNameKey: current}
+	if exists {
+		err = cluster.client.Update(ctx, alias)
+	} else {
+		alias.Labels = map[string]string{SourceConfigMapLabel: source}
+		alias.Annotations = map[string]string{CreatedByControllerAnnotation: "true", AliasAnnotation: "true"}
+		err = cluster.client.Create(ctx, alias)
+	}
+	if err != nil {
+		return fmt.Errorf("write alias ConfigMap: %w", err)
+	}
+	return nil
+}
+
+// publishCurrentVersion annotates the syncer with the name of the version of
+// the content being propagated, once every target holds it. While a rollout
+// is in progress or targets failed, the previous version stays published.
+// Only the metadata is patched, the syncer itself is left as it is so that its
+// status can still be patched.
+func (r *ConfigMapSyncerReconciler) publishCurrentVersion(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	result *syncResult,
+) error {
+	spec := configMapSyncer.GetSpec()
+	if spec.ImmutableTargets == nil || spec.ImmutableTargets.Pointer == ImmutablePointerAliasConfigMap ||
+		configMapSyncer.GetStatus().CurrentRevision == "" {
+		return nil
+	}
+	if rolloutInProgress(configMapSyncer) != nil || result.failed() > 0 || result.pending() > 0 {
+		return nil
+	}
+	target := spec.TargetConfigMapName
+	if target == "" {
+		target = spec.MasterConfigMap.Name
+	}
+	current := versionedTargetName(target, configMapSyncer.GetStatus().CurrentRevision)
+	if configMapSyncer.GetAnnotations()[ImmutablePointerAnnotation] == current {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"annotations": map[string]string{ImmutablePointerAnnotation: current}},
+	})
+	if err != nil {
+		return err
+	}
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(syncv1alpha1.GroupVersion.WithKind(kindOf(configMapSyncer)))
+	obj.SetName(configMapSyncer.GetName())
+	obj.SetNamespace(configMapSyncer.GetNamespace())
+	if err := r.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return newTransientError("annotate current target version", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Immutable targets", func() {
+	const (
+		resourceName    = "immutable-syncer"
+		masterName      = "immutable-config"
+		targetNamespace = "immutable-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+	masterKey := types.NamespacedName{Name: masterName, Namespace: "default"}
+
+	BeforeEach(func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		createMaster(ctx, masterName, map[string]string{"color": "blue"})
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+		master := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: "default"}}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, master))).To(Succeed())
+		Expect(k8sClient.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace(targetNamespace))).To(Succeed())
+	})
+
+	It("should write a new version per content and delete old ones", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				ImmutableTargets: &syncv1alpha1.ImmutableTargets{Keep: 1, Pointer: ImmutablePointerAliasConfigMap},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		currentVersion := func() string {
+			reconcileOnce(ctx, controllerReconciler, resource)
+			return versionedTargetName(masterName, resource.Status.CurrentRevision)
+		}
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		first := currentVersion()
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: first, Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Immutable).To(HaveValue(BeTrue()))
+		Expect(target.Data).To(HaveKeyWithValue("color", "blue"))
+
+		master := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, masterKey, master)).To(Succeed())
+		master.Data["color"] = "green"
+		Expect(k8sClient.Update(ctx, master)).To(Succeed())
+		second := currentVersion()
+		Expect(second).NotTo(Equal(first))
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: second, Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("color", "green"))
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: first, Namespace: targetNamespace}, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		alias := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, alias)).To(Succeed())
+		Expect(alias.Data).To(HaveKeyWithValue(AliasNameKey, second))
+	})
+
+	It("should keep the configured number of versions", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				ImmutableTargets: &syncv1alpha1.ImmutableTargets{Keep: 2},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		currentVersion := func(color string) string {
+			// Versions are ordered by their creation timestamp, which has a resolution of a second
+			time.Sleep(time.Second)
+			master := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, masterKey, master)).To(Succeed())
+			master.Data["color"] = color
+			Expect(k8sClient.Update(ctx, master)).To(Succeed())
+			reconcileOnce(ctx, controllerReconciler, resource)
+			return versionedTargetName(masterName, resource.Status.CurrentRevision)
+		}
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		first := currentVersion("blue")
+		second := currentVersion("green")
+		third := currentVersion("red")
+
+		versions := &corev1.ConfigMapList{}
+		Expect(k8sClient.List(ctx, versions, client.InNamespace(targetNamespace))).To(Succeed())
+		names := make([]string, 0, len(versions.Items))
+		for _, version := range versions.Items {
+			names = append(names, version.Name)
+		}
+		Expect(names).To(ContainElements(second, third))
+		Expect(names).NotTo(ContainElement(first))
+		Expect(resource.Annotations).To(HaveKeyWithValue(ImmutablePointerAnnotation, third))
+	})
+
+	It("should fail on a version it cannot update", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				ImmutableTargets: &syncv1alpha1.ImmutableTargets{},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		for range 2 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+
+		// Replace the version with an immutable ConfigMap of different content
+		key := types.NamespacedName{Name: versionedTargetName(masterName, resource.Status.CurrentRevision), Namespace: targetNamespace}
+		Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}})).To(Succeed())
+		impostor := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Data:       map[string]string{"color": "purple"},
+			Immutable:  ptr.To(true),
+		}
+		Expect(k8sClient.Create(ctx, impostor)).To(Succeed())
+		reconcileOnce(ctx, controllerReconciler, resource)
+
+		Expect(resource.Status.SyncStatuses).To(ContainElement(And(
+			HaveField("ConfigMapName", key.Name),
+			HaveField("Status", SyncStatusFailed),
+			HaveField("Message", ContainSubstring("Failed to update")),
+		)))
+		Expect(k8sClient.Get(ctx, key, impostor)).To(Succeed())
+		Expect(impostor.Data).To(Equal(map[string]string{"color": "purple"}))
+	})
+
+	It("should replace its plain target with the alias", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				PrunePolicy:      PrunePolicyDelete,
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		for range 2 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+		aliasKey := types.NamespacedName{Name: masterName, Namespace: targetNamespace}
+		Expect(k8sClient.Get(ctx, aliasKey, &corev1.ConfigMap{})).To(Succeed())
+
+		resource.Spec.ImmutableTargets = &syncv1alpha1.ImmutableTargets{Pointer: ImmutablePointerAliasConfigMap}
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		reconcileOnce(ctx, controllerReconciler, resource)
+
+		alias := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, aliasKey, alias)).To(Succeed())
+		Expect(alias.Data).To(Equal(map[string]string{
+			AliasNameKey: versionedTargetName(masterName, resource.Status.CurrentRevision),
+		}))
+		Expect(alias.Annotations).To(HaveKeyWithValue(AliasAnnotation, "true"))
+		Expect(alias.Annotations).NotTo(HaveKey(ContentHashAnnotation))
+		Expect(resource.Status.SyncStatuses).NotTo(ContainElement(HaveField("Status", SyncStatusPruned)))
+	})
+
+	It("should reject target names its versions would not fit into", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:     syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetConfigMapName: strings.Repeat("a", 243),
+				ImmutableTargets:    &syncv1alpha1.ImmutableTargets{},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(MatchError(ContainSubstring("at most 242 characters")))
+	})
+
+	It("should not overwrite a ConfigMap that is not its alias", func() {
+		foreign := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: targetNamespace},
+			Data:       map[string]string{"owner": "someone else"},
+		}
+		Expect(k8sClient.Create(ctx, foreign)).To(Succeed())
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				ImmutableTargets: &syncv1alpha1.ImmutableTargets{Pointer: ImmutablePointerAliasConfigMap},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		for range 2 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreign), foreign)).To(Succeed())
+		Expect(foreign.Data).To(Equal(map[string]string{"owner": "someone else"}))
+		Expect(resource.Status.SyncStatuses).To(ContainElement(And(
+			HaveField("Status", SyncStatusFailed),
+			HaveField("Message", ContainSubstring("is not the alias")),
+		)))
+	})
+})
@@ -0,0 +1,321 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/sources"
+)
+
+const (
+	// ConditionReasonSourceUnavailable is the reason when the external source of the master content cannot be read
+	ConditionReasonSourceUnavailable = "SourceUnavailable"
+
+	// ConditionReasonSourceFetched is the reason when the external source of the master content was read
+	ConditionReasonSourceFetched = "SourceFetched"
+)
+
+// loadMaster returns the master ConfigMap of the syncer together with the
+// MasterAvailable condition. The master is read from the local cluster, a
+// remote cluster or an external source. When it is not available the
+// condition says why and no ConfigMap is returned; errors are only returned
+// for failures that are worth retrying with backoff.
+func (r *ConfigMapSyncerReconciler) loadMaster(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+) (*corev1.ConfigMap, metav1.Condition, error) {
+	logger := log.FromContext(ctx)
+	spec := configMapSyncer.GetSpec()
+	masterConfigMapKey := types.NamespacedName{
+		Name:      spec.MasterConfigMap.Name,
+		Namespace: spec.MasterConfigMap.Namespace,
+	}
+
+	// The content may come from outside the cluster
+	if spec.Source != nil {
+		r.stopWatchingRemoteMaster(configMapSyncer)
+		return r.loadSource(ctx, configMapSyncer, masterConfigMapKey)
+	}
+	configMapSyncer.GetStatus().Source = nil
+	r.gitSource().Release(string(configMapSyncer.GetUID()))
+
+	// The master may be pulled from a remote cluster
+	masterKind := KindConfigMap
+	if spec.MasterConfigMap.Kind == KindSecret {
+		masterKind = KindSecret
+	}
+	var masterReader client.Reader = r.apiReader()
+	masterLocation := ""
+	if ref := spec.MasterConfigMap.KubeconfigSecretRef; ref != nil {
+		remote, err := r.connect(ctx, configMapSyncer, *ref)
+		if err != nil {
+			logger.Error(err, "Failed to connect to the cluster of the master")
+			return nil, unavailable(ConditionReasonMasterClusterUnreachable,
+				fmt.Sprintf("Cluster of master %s %s is unavailable: %v", masterKind, masterConfigMapKey, err)), nil
+		}
+		// Remote Secret masters are only re-read on the sync interval
+		if masterKind == KindConfigMap {
+			r.watchRemoteMaster(configMapSyncer, remote, masterConfigMapKey)
+		} else {
+			r.stopWatchingRemoteMaster(configMapSyncer)
+		}
+		masterReader = remote
+		masterLocation = fmt.Sprintf(" in cluster of Secret %s", kubeconfigSecretKey(configMapSyncer, *ref))
+	} else {
+		r.stopWatchingRemoteMaster(configMapSyncer)
+	}
+
+	masterConfigMap, err := readAsConfigMap(ctx, masterReader, masterConfigMapKey, masterKind)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, unavailable(ConditionReasonMasterConfigMapNotFound,
+				fmt.Sprintf("Master %s %s not found%s", masterKind, masterConfigMapKey, masterLocation)), nil
+		}
+		return nil, metav1.Condition{}, newTransientError("get master "+masterKind, err)
+	}
+
+	return masterConfigMap, metav1.Condition{
+		Type:    ConditionTypeMasterAvailable,
+		Status:  metav1.ConditionTrue,
+		Reason:  ConditionReasonMasterConfigMapFound,
+		Message: fmt.Sprintf("Master %s %s is available%s", masterKind, masterConfigMapKey, masterLocation),
+	}, nil
+}
+
+// loadSource reads the master content from the external source of the syncer
+// into an in-memory master ConfigMap. The revision of the source takes the
+// place of the resourceVersion and is recorded in the status.
+func (r *ConfigMapSyncerReconciler) loadSource(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	masterConfigMapKey types.NamespacedName,
+) (*corev1.ConfigMap, metav1.Condition, error) {
+	source := configMapSyncer.GetSpec().Source
+
+	var (
+		content *sources.Content
+		err     error
+	)
+	if source.Git == nil {
+		r.gitSource().Release(string(configMapSyncer.GetUID()))
+	}
+	switch {
+	case source.Git != nil:
+		content, err = r.gitSource().Fetch(ctx, string(configMapSyncer.GetUID()), *source.Git)
+	case source.HTTP != nil:
+		content, err = r.fetchHTTP(ctx, configMapSyncer, *source.HTTP)
+	case source.OCI != nil:
+		content, err = r.fetchOCI(ctx, configMapSyncer, *source.OCI)
+	default:
+		err = fmt.Errorf("no source is configured")
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to fetch source")
+		return nil, unavailable(ConditionReasonSourceUnavailable, fmt.Sprintf("Failed to fetch source: %v", err)), nil
+	}
+
+	configMapSyncer.GetStatus().Source = &syncv1alpha1.SourceStatus{
+		Revision:      content.Revision,
+		LastFetchTime: &metav1.Time{Time: time.Now()},
+	}
+	masterConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            masterConfigMapKey.Name,
+			Namespace:       masterConfigMapKey.Namespace,
+			ResourceVersion: content.Revision,
+		},
+		Data:       content.Data,
+		BinaryData: content.BinaryData,
+	}
+	return masterConfigMap, metav1.Condition{
+		Type:    ConditionTypeMasterAvailable,
+		Status:  metav1.ConditionTrue,
+		Reason:  ConditionReasonSourceFetched,
+		Message: fmt.Sprintf("Source fetched at revision %s", content.Revision),
+	}, nil
+}
+
+// gitSource returns the Git source, creating one that caches its mirrors in
+// the temporary directory if none is configured
+func (r *ConfigMapSyncerReconciler) gitSource() *sources.Git {
+	if r.Git == nil {
+		r.Git = sources.NewGit(filepath.Join(os.TempDir(), "configmap-sync", "git"))
+	}
+	return r.Git
+}
+
+// httpSource returns the HTTP source, creating one if none is configured
+func (r *ConfigMapSyncerReconciler) httpSource() *sources.HTTP {
+	if r.HTTP == nil {
+		r.HTTP = sources.NewHTTP()
+	}
+	return r.HTTP
+}
+
+// ociSource returns the OCI source, creating one if none is configured
+func (r *ConfigMapSyncerReconciler) ociSource() *sources.OCI {
+	if r.OCI == nil {
+		r.OCI = sources.NewOCI()
+	}
+	return r.OCI
+}
+
+// validateSource rejects sources a syncer may not read. Local repositories are
+// files of the controller, only ClusterConfigMapSyncers may read them, and
+// namespaced ConfigMapSyncers may only use HTTP and OCI sources on the hosts
+// the admin allows. Credentials follow the same namespace rules as kubeconfig
+// Secrets.
+func (r *ConfigMapSyncerReconciler) validateSource(configMapSyncer syncv1alpha1.Syncer) error {
+	source := configMapSyncer.GetSpec().Source
+	if source == nil {
+		return nil
+	}
+	if source.HTTP != nil && source.HTTP.SecretRef != nil {
+		if err := validateSecretNamespace(configMapSyncer, "spec.source.http.secretRef.namespace", source.HTTP.SecretRef.Namespace); err != nil {
+			return err
+		}
+	}
+	if source.OCI != nil && source.OCI.SecretRef != nil {
+		if err := validateSecretNamespace(configMapSyncer, "spec.source.oci.secretRef.namespace", source.OCI.SecretRef.Namespace); err != nil {
+			return err
+		}
+	}
+	if configMapSyncer.GetNamespace() == "" {
+		return nil
+	}
+	if source.Git != nil {
+		host, err := sources.GitHost(source.Git.URL)
+		if err != nil {
+			return newValidationError("spec.source.git.url", err)
+		}
+		if !r.Config.AllowsSourceHost(host) {
+			return newValidationError("spec.source.git.url", fmt.Errorf(
+				"a ConfigMapSyncer may only read from the hosts allowed with --source-hosts, not %q", host))
+		}
+	}
+	if source.HTTP != nil {
+		u, err := url.Parse(source.HTTP.URL)
+		if err != nil {
+			return newValidationError("spec.source.http.url", err)
+		}
+		if !r.Config.AllowsSourceHost(u.Host) {
+			return newValidationError("spec.source.http.url", fmt.Errorf(
+				"a ConfigMapSyncer may only read from the hosts allowed with --source-hosts, not %q", u.Host))
+		}
+	}
+	if source.OCI != nil {
+		host, _, _ := strings.Cut(source.OCI.Repository, "/")
+		if !r.Config.AllowsSourceHost(host) {
+			return newValidationError("spec.source.oci.repository", fmt.Errorf(
+				"a ConfigMapSyncer may only read from the hosts allowed with --source-hosts, not %q", host))
+		}
+	}
+	return nil
+}
+
+// forgetSource drops the cached content of the source of a deleted syncer
+func (r *ConfigMapSyncerReconciler) forgetSource(configMapSyncer syncv1alpha1.Syncer) {
+	r.gitSource().Release(string(configMapSyncer.GetUID()))
+	source := configMapSyncer.GetSpec().Source
+	switch {
+	case source == nil:
+	case source.HTTP != nil:
+		r.httpSource().Forget(source.HTTP.URL)
+	case source.OCI != nil:
+		r.ociSource().Forget(source.OCI.Repository)
+	}
+}
+
+// sourceSecret reads the Secret with the credentials of a source
+func (r *ConfigMapSyncerReconciler) sourceSecret(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	ref *syncv1alpha1.SecretReference,
+) (*corev1.Secret, error) {
+	key := secretKey(configMapSyncer, ref.Name, ref.Namespace)
+	secret := &corev1.Secret{}
+	if err := r.apiReader().Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+	return secret, nil
+}
+
+// fetchHTTP reads the master content from an HTTP source
+func (r *ConfigMapSyncerReconciler) fetchHTTP(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	source syncv1alpha1.HTTPSource,
+) (*sources.Content, error) {
+	var auth *sources.HTTPAuth
+	if source.SecretRef != nil {
+		secret, err := r.sourceSecret(ctx, configMapSyncer, source.SecretRef)
+		if err != nil {
+			return nil, err
+		}
+		auth = &sources.HTTPAuth{
+			Token:    string(secret.Data["token"]),
+			Username: string(secret.Data["username"]),
+			Password: string(secret.Data["password"]),
+		}
+	}
+	return r.httpSource().Fetch(ctx, source, auth)
+}
+
+// fetchOCI reads the master content from an OCI source
+func (r *ConfigMapSyncerReconciler) fetchOCI(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	source syncv1alpha1.OCISource,
+) (*sources.Content, error) {
+	var auth *sources.OCIAuth
+	if source.SecretRef != nil {
+		secret, err := r.sourceSecret(ctx, configMapSyncer, source.SecretRef)
+		if err != nil {
+			return nil, err
+		}
+		auth = &sources.OCIAuth{
+			Username: string(secret.Data["username"]),
+			Password: string(secret.Data["password"]),
+		}
+	}
+	return r.ociSource().Fetch(ctx, source, auth)
+}
+
+// unavailable returns a MasterAvailable condition reporting the master as unavailable
+func unavailable(reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    ConditionTypeMasterAvailable,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+	"github.com/devShahriar/configmap-sync-controller/internal/sources"
+)
+
+var _ = Describe("External sources", func() {
+	const (
+		resourceName    = "git-syncer"
+		masterName      = "git-config"
+		targetNamespace = "git-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+	})
+
+	It("should sync the files of a Git repository and report the commit", func() {
+		root := GinkgoT().TempDir()
+		work := filepath.Join(root, "work")
+		Expect(os.MkdirAll(filepath.Join(work, "shared"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(work, "shared", "app.properties"), []byte("color=blue"), 0o644)).To(Succeed())
+		git(root, "init", "-q", "-b", "main", work)
+		git(work, "add", "-A")
+		git(work, "commit", "-q", "-m", "initial")
+		commit := git(work, "rev-parse", "HEAD")
+
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				Source: &syncv1alpha1.MasterSource{Git: &syncv1alpha1.GitSource{
+					URL:    "file://" + work,
+					Branch: "main",
+					Path:   "shared",
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+		controllerReconciler := &ClusterConfigMapSyncerReconciler{ConfigMapSyncerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Git:    sources.NewGit(filepath.Join(root, "cache")),
+		}}
+		for range 2 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Data).To(Equal(map[string]string{"app.properties": "color=blue"}))
+		Expect(target.Annotations).To(HaveKeyWithValue(SourceResourceVersionAnnotation, commit))
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.Source).NotTo(BeNil())
+		Expect(resource.Status.Source.Revision).To(Equal(commit))
+		Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionTypeMasterAvailable)).To(BeTrue())
+	})
+
+	It("should only let namespaced syncers read remote repositories of allowed hosts", func() {
+		reconciler := &ConfigMapSyncerReconciler{Config: config.Default()}
+		reconciler.Config.SourceHosts = []string{"git.example.com"}
+		validate := func(repoURL string) error {
+			return reconciler.validateSource(&syncv1alpha1.ConfigMapSyncer{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: syncv1alpha1.ConfigMapSyncerSpec{
+					Source: &syncv1alpha1.MasterSource{Git: &syncv1alpha1.GitSource{URL: repoURL}},
+				},
+			})
+		}
+		Expect(validate("https://git.example.com/config.git")).To(Succeed())
+		Expect(validate("git@git.example.com:config.git")).To(Succeed())
+		Expect(validate("git@169.254.169.254:config.git")).To(MatchError(ContainSubstring("--source-hosts")))
+		Expect(validate("http://10.0.0.1/config.git")).To(MatchError(ContainSubstring("--source-hosts")))
+		Expect(validate("/srv/config.git")).To(MatchError(ContainSubstring("not a remote repository")))
+		Expect(IsValidationError(validate("file:///srv/config.git"))).To(BeTrue())
+	})
+
+	It("should sync a document served over HTTP with credentials from a Secret", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if user, password, ok := req.BasicAuth(); !ok || user != "sync" || password != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte("color: blue\nsize: large\n"))
+		}))
+		DeferCleanup(server.Close)
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-service", Namespace: "default"},
+			Data:       map[string][]byte{"username": []byte("sync"), "password": []byte("s3cret")},
+		}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, secret))).To(Succeed())
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				Source: &syncv1alpha1.MasterSource{HTTP: &syncv1alpha1.HTTPSource{
+					URL:       server.URL,
+					Format:    sources.FormatYAML,
+					SecretRef: &syncv1alpha1.SecretReference{Name: "config-service", Namespace: "default"},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+		controllerReconciler := newClusterReconciler()
+		for range 2 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Data).To(Equal(map[string]string{"color": "blue", "size": "large"}))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.Source.Revision).To(Equal(`"v1"`))
+	})
+})
@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// SyncAnnotation on a namespace set to SyncDisabled keeps every syncer out of it
+	SyncAnnotation = "conf-sync.com/sync"
+
+	// SyncDisabled is the SyncAnnotation value that opts a namespace out of syncing
+	SyncDisabled = "disabled"
+
+	// AllowedSourcesAnnotation on a namespace restricts the master ConfigMaps that may
+	// be synced into it. It holds a comma separated list of <namespace>/<name>
+	// entries, <namespace>/* allows every master in a namespace.
+	AllowedSourcesAnnotation = "conf-sync.com/allowed-sources"
+)
+
+// masterOrigin describes where a master that is not a ConfigMap of the local
+// cluster is read from, or returns an empty string for local masters. The
+// namespace and name of such masters are chosen by the syncer and say nothing
+// about who owns the content.
+func masterOrigin(configMapSyncer syncv1alpha1.Syncer) string {
+	spec := configMapSyncer.GetSpec()
+	switch {
+	case spec.Source != nil:
+		return "an external source"
+	case spec.MasterConfigMap.KubeconfigSecretRef != nil:
+		return "a remote cluster"
+	}
+	return ""
+}
+
+// holdsMaster reports whether the namespace of the cluster holds the master
+// itself. Targets are never written there, a master that is read from another
+// cluster or an external source does not occupy any local namespace.
+func holdsMaster(cluster *targetCluster, origin, namespace, masterNamespace string) bool {
+	return cluster.isLocal() && origin == "" && namespace == masterNamespace
+}
+
+// namespaceSkipReason returns why the master ConfigMap must not be synced into
+// the namespace according to the namespace's annotations, or an empty string
+// if it may be. Allowed sources only ever match local masters, origin names
+// where any other master is read from.
+func namespaceSkipReason(namespace metav1.Object, masterConfigMap *corev1.ConfigMap, origin string) string {
+	if strings.EqualFold(namespace.GetAnnotations()[SyncAnnotation], SyncDisabled) {
+		return fmt.Sprintf("namespace %s has opted out of syncing with %s: %s",
+			namespace.GetName(), SyncAnnotation, SyncDisabled)
+	}
+
+	allowedSources, ok := namespace.GetAnnotations()[AllowedSourcesAnnotation]
+	if !ok {
+		return ""
+	}
+	if origin != "" {
+		return fmt.Sprintf("namespace %s only accepts the master ConfigMaps in its %s annotation, the master is read from %s",
+			namespace.GetName(), AllowedSourcesAnnotation, origin)
+	}
+	for _, source := range strings.Split(allowedSources, ",") {
+		source = strings.TrimSpace(source)
+		if source == masterConfigMap.Namespace+"/"+masterConfigMap.Name || source == masterConfigMap.Namespace+"/*" {
+			return ""
+		}
+	}
+	return fmt.Sprintf("namespace %s only accepts %s in its %s annotation",
+		namespace.GetName(), allowedSources, AllowedSourcesAnnotation)
+}
+
+// skipNamespaceReason looks up the target namespace and returns why the master
+// ConfigMap must not be synced into it, or an empty string if it may be.
+// Namespaces that do not exist are not skipped, writing to them fails instead.
+func (r *ConfigMapSyncerReconciler) skipNamespaceReason(
+	ctx context.Context,
+	cluster *targetCluster,
+	namespace string,
+	masterConfigMap *corev1.ConfigMap,
+	origin string,
+) (string, error) {
+	ns := namespaceMetadata()
+	if err := cluster.client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", newTransientError("get namespace", err)
+	}
+	return namespaceSkipReason(ns, masterConfigMap, origin), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Namespace opt-out", func() {
+	master := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"}}
+
+	namespace := func(annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app1", Annotations: annotations}}
+	}
+
+	It("should sync into namespaces without annotations", func() {
+		Expect(namespaceSkipReason(namespace(nil), master, "")).To(BeEmpty())
+	})
+
+	It("should skip namespaces that disabled syncing", func() {
+		reason := namespaceSkipReason(namespace(map[string]string{SyncAnnotation: SyncDisabled}), master, "")
+		Expect(reason).To(ContainSubstring("opted out"))
+	})
+
+	It("should only sync allowed sources", func() {
+		Expect(namespaceSkipReason(namespace(map[string]string{
+			AllowedSourcesAnnotation: "platform/base, default/app-config",
+		}), master, "")).To(BeEmpty())
+		Expect(namespaceSkipReason(namespace(map[string]string{
+			AllowedSourcesAnnotation: "default/*",
+		}), master, "")).To(BeEmpty())
+		Expect(namespaceSkipReason(namespace(map[string]string{
+			AllowedSourcesAnnotation: "platform/base",
+		}), master, "")).To(ContainSubstring("only accepts platform/base"))
+	})
+
+	It("should not let remote or external masters pass as allowed sources", func() {
+		Expect(namespaceSkipReason(namespace(map[string]string{
+			AllowedSourcesAnnotation: "default/app-config",
+		}), master, "a remote cluster")).To(ContainSubstring("read from a remote cluster"))
+		Expect(namespaceSkipReason(namespace(nil), master, "an external source")).To(BeEmpty())
+	})
+
+	It("should not count skipped namespaces as targets or failures", func() {
+		result := &syncResult{
+			statuses: []syncv1alpha1.SyncStatus{
+				{ConfigMapName: "app-config", Namespace: "app1", Status: SyncStatusSynced},
+				{ConfigMapName: "app-config", Namespace: "app2", Status: SyncStatusSkipped},
+			},
+			skippedNamespaces: map[string]bool{"app2": true},
+		}
+		Expect(result.total()).To(Equal(1))
+		Expect(result.failed()).To(Equal(0))
+		Expect(result.skipped()).To(Equal(1))
+	})
+})
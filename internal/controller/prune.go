@@ -0,0 +1,267 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// EventReasonTargetPruned is the event reason when a target that is no longer selected was deleted
+	EventReasonTargetPruned = "TargetPruned"
+
+	// EventReasonTargetReleased is the event reason when a target that is no longer selected was released
+	EventReasonTargetReleased = "TargetReleased"
+
+	// EventReasonPruneFailed is the event reason when a target that is no longer selected could not be pruned
+	EventReasonPruneFailed = "PruneFailed"
+)
+
+// pruneStaleTargets deletes or releases, depending on the prune policy, the
+// targets of the previous sync that were not selected by this sync. The
+// outcome for each of them is appended to the result.
+func (r *ConfigMapSyncerReconciler) pruneStaleTargets(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	previous []syncv1alpha1.SyncStatus,
+	result *syncResult,
+	clusters map[string]*targetCluster,
+) {
+	selected := make(map[targetKey]bool, len(result.statuses))
+	for _, status := range result.statuses {
+		selected[targetKeyOf(status)] = true
+	}
+
+	pruned := r.pruneTargets(ctx, configMapSyncer, previous, clusters, func(status syncv1alpha1.SyncStatus) bool {
+		namespace := namespaceKey(status.Cluster, status.Namespace)
+		return selected[targetKeyOf(status)] || result.incompleteClusters[status.Cluster] ||
+			result.incompleteNamespaces[namespace] || result.skippedNamespaces[namespace] ||
+			result.heldNamespaces[namespace] || result.versions[targetKeyOf(status)]
+	})
+	result.statuses = append(result.statuses, pruned...)
+	if r.dryRun(configMapSyncer) {
+		result.plan = append(result.plan, plannedPrunes(pruned)...)
+	}
+}
+
+// pruneOutOfScopeTargets is used on error paths, where no new sync statuses are
+// available. It prunes the targets of the previous sync the current spec can
+// no longer select and returns the remaining statuses together with the prune
+// outcomes, so that the status does not go stale.
+func (r *ConfigMapSyncerReconciler) pruneOutOfScopeTargets(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	previous []syncv1alpha1.SyncStatus,
+	clusters map[string]*targetCluster,
+) []syncv1alpha1.SyncStatus {
+	var statuses []syncv1alpha1.SyncStatus
+	for _, status := range previous {
+		if status.Status != SyncStatusPruned && inScope(configMapSyncer, status) {
+			statuses = append(statuses, status)
+		}
+	}
+
+	pruned := r.pruneTargets(ctx, configMapSyncer, previous, clusters, func(status syncv1alpha1.SyncStatus) bool {
+		return inScope(configMapSyncer, status)
+	})
+	return append(statuses, pruned...)
+}
+
+// targetKey identifies a target across clusters and kinds
+type targetKey struct {
+	cluster string
+	kind    string
+	types.NamespacedName
+}
+
+// targetKeyOf returns the key of the target of a sync status
+func targetKeyOf(status syncv1alpha1.SyncStatus) targetKey {
+	return targetKey{
+		cluster:        status.Cluster,
+		kind:           kindOfStatus(status),
+		NamespacedName: types.NamespacedName{Namespace: status.Namespace, Name: status.ConfigMapName},
+	}
+}
+
+// pruneTargets prunes every target of the previous sync for which selected
+// returns false and returns the outcome for each of them. Targets in clusters
+// that are not given are left alone, they cannot be reached.
+func (r *ConfigMapSyncerReconciler) pruneTargets(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	previous []syncv1alpha1.SyncStatus,
+	clusters map[string]*targetCluster,
+	selected func(syncv1alpha1.SyncStatus) bool,
+) []syncv1alpha1.SyncStatus {
+	logger := log.FromContext(ctx)
+
+	policy := configMapSyncer.GetSpec().PrunePolicy
+	if policy == "" {
+		policy = PrunePolicyRelease
+	}
+
+	var statuses []syncv1alpha1.SyncStatus
+	for _, status := range previous {
+		if status.ConfigMapName == "" || status.Status == SyncStatusPruned || status.Status == SyncStatusSkipped ||
+			selected(status) {
+			continue
+		}
+		cluster, ok := clusters[status.Cluster]
+		if !ok {
+			continue
+		}
+
+		kind := kindOfStatus(status)
+		key := types.NamespacedName{Namespace: status.Namespace, Name: status.ConfigMapName}
+		syncStatus := syncv1alpha1.SyncStatus{
+			Cluster:       status.Cluster,
+			Kind:          status.Kind,
+			ConfigMapName: key.Name,
+			Namespace:     key.Namespace,
+			Status:        SyncStatusPruned,
+			LastSyncTime:  &metav1.Time{Time: time.Now()},
+		}
+
+		deleted, err := r.pruneTarget(ctx, cluster, key, kind, policy)
+		if cluster.dryRun {
+			// Only planned, the outcome is reported in the dry-run status
+			switch {
+			case err != nil:
+				syncStatus.Status = SyncStatusFailed
+				syncStatus.LastSyncTime = nil
+				syncStatus.Message = err.Error()
+			case deleted:
+				syncStatus.Message = "Would be deleted since it is no longer selected"
+			default:
+				syncStatus.Message = "Would be released since it is no longer selected"
+			}
+			statuses = append(statuses, syncStatus)
+			continue
+		}
+		if err != nil {
+			logger.Error(err, "Failed to prune target", "cluster", cluster.displayName(), "kind", kind, "namespace", key.Namespace, "name", key.Name)
+			syncStatus.Status = SyncStatusFailed
+			syncStatus.LastSyncTime = nil
+			syncStatus.Message = fmt.Sprintf("Failed to prune %s: %v", kind, err)
+			r.event(configMapSyncer, corev1.EventTypeWarning, EventReasonPruneFailed,
+				"Failed to prune %s %s: %v", kind, key, err)
+		} else if deleted {
+			logger.Info("Deleted target that is no longer selected", "kind", kind, "namespace", key.Namespace, "name", key.Name)
+			syncStatus.Message = "Deleted since it is no longer selected"
+			r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonTargetPruned,
+				"Deleted %s %s since it is no longer selected", kind, key)
+		} else {
+			logger.Info("Released target that is no longer selected", "kind", kind, "namespace", key.Namespace, "name", key.Name)
+			syncStatus.Message = "Released since it is no longer selected"
+			r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonTargetReleased,
+				"Released %s %s since it is no longer selected", kind, key)
+		}
+
+		statuses = append(statuses, syncStatus)
+	}
+	return statuses
+}
+
+// pruneTarget deletes or releases a single target and reports whether it was
+// deleted. Only targets the controller created are deleted, adopted ones are
+// always released. Targets that are gone or no longer carry the source label
+// are left alone.
+func (r *ConfigMapSyncerReconciler) pruneTarget(
+	ctx context.Context,
+	cluster *targetCluster,
+	key types.NamespacedName,
+	kind string,
+	policy string,
+) (bool, error) {
+	target := newTargetObject(kind)
+	if err := cluster.reader.Get(ctx, key, target); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if _, ok := target.GetLabels()[SourceConfigMapLabel]; !ok {
+		return false, nil
+	}
+
+	if policy == PrunePolicyDelete && target.GetAnnotations()[CreatedByControllerAnnotation] == "true" {
+		resourceVersion := target.GetResourceVersion()
+		err := cluster.client.Delete(ctx, target, client.Preconditions{ResourceVersion: &resourceVersion})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return err == nil, err
+	}
+
+	released := target.DeepCopyObject().(client.Object)
+	labels := released.GetLabels()
+	delete(labels, SourceConfigMapLabel)
+	released.SetLabels(labels)
+	annotations := released.GetAnnotations()
+	delete(annotations, ContentHashAnnotation)
+	delete(annotations, SourceResourceVersionAnnotation)
+	delete(annotations, CreatedByControllerAnnotation)
+	released.SetAnnotations(annotations)
+	return false, cluster.client.Patch(ctx, released, client.MergeFrom(target))
+}
+
+// inScope reports whether the current spec can still select the target of a
+// recorded sync status. Targets matched by a selector are assumed to be in
+// scope as long as their namespace is.
+func inScope(configMapSyncer syncv1alpha1.Syncer, status syncv1alpha1.SyncStatus) bool {
+	spec := *configMapSyncer.GetSpec()
+	if status.Cluster != "" && !slices.ContainsFunc(spec.Clusters, func(cluster syncv1alpha1.ClusterReference) bool {
+		return cluster.Name == status.Cluster
+	}) {
+		return false
+	}
+	if status.ConfigMapName != "" && kindOfStatus(status) != targetKind(configMapSyncer) {
+		return false
+	}
+	if len(spec.TargetNamespaces) > 0 && !slices.Contains(spec.TargetNamespaces, status.Namespace) {
+		return false
+	}
+	if spec.TargetSelector == nil && status.ConfigMapName != "" {
+		targetName := spec.TargetConfigMapName
+		if targetName == "" {
+			targetName = spec.MasterConfigMap.Name
+		}
+		return status.ConfigMapName == targetName || spec.ImmutableTargets != nil && isVersionOf(status.ConfigMapName, targetName)
+	}
+	return true
+}
+
+// event records an event on the syncer if a recorder is configured
+func (r *ConfigMapSyncerReconciler) event(
+	configMapSyncer syncv1alpha1.Syncer,
+	eventType, reason, messageFmt string,
+	args ...interface{},
+) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(configMapSyncer, eventType, reason, messageFmt, args...)
+}
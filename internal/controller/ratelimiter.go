@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+)
+
+// newRateLimiter builds the workqueue rate limiter for the controller. A failing
+// ConfigMapSyncer is retried with exponential backoff, and in addition every
+// ConfigMapSyncer has its own token bucket so that one misbehaving resource
+// cannot starve the others of workers.
+func newRateLimiter(cfg config.ControllerConfig) workqueue.TypedRateLimiter[reconcile.Request] {
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](
+			cfg.BaseBackoff.Duration,
+			cfg.MaxBackoff.Duration,
+		),
+		newPerItemBucketRateLimiter[reconcile.Request](rate.Limit(cfg.SyncerQPS), cfg.SyncerBurst),
+	)
+}
+
+// perItemBucketRateLimiter keeps a separate token bucket for every item
+type perItemBucketRateLimiter[T comparable] struct {
+	mu       sync.Mutex
+	limiters map[T]*rate.Limiter
+	qps      rate.Limit
+	burst    int
+}
+
+var _ workqueue.TypedRateLimiter[reconcile.Request] = &perItemBucketRateLimiter[reconcile.Request]{}
+
+func newPerItemBucketRateLimiter[T comparable](qps rate.Limit, burst int) *perItemBucketRateLimiter[T] {
+	return &perItemBucketRateLimiter[T]{
+		limiters: make(map[T]*rate.Limiter),
+		qps:      qps,
+		burst:    burst,
+	}
+}
+
+// When returns how long the item has to wait for a token from its bucket
+func (r *perItemBucketRateLimiter[T]) When(item T) time.Duration {
+	r.mu.Lock()
+	limiter, ok := r.limiters[item]
+	if !ok {
+		limiter = rate.NewLimiter(r.qps, r.burst)
+		r.limiters[item] = limiter
+	}
+	r.mu.Unlock()
+
+	return limiter.Reserve().Delay()
+}
+
+// NumRequeues is not tracked by the bucket limiter
+func (r *perItemBucketRateLimiter[T]) NumRequeues(item T) int {
+	return 0
+}
+
+// Forget drops the bucket of an item, so that deleted ConfigMapSyncers do not
+// leak limiters. An item that fails again starts with a full bucket.
+func (r *perItemBucketRateLimiter[T]) Forget(item T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.limiters, item)
+}
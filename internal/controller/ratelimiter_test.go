@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Per-item bucket rate limiter", func() {
+	first := reconcile.Request{NamespacedName: types.NamespacedName{Name: "first"}}
+	second := reconcile.Request{NamespacedName: types.NamespacedName{Name: "second"}}
+
+	It("should throttle an item once its burst is used up", func() {
+		limiter := newPerItemBucketRateLimiter[reconcile.Request](rate.Limit(1), 2)
+		Expect(limiter.When(first)).To(BeZero())
+		Expect(limiter.When(first)).To(BeZero())
+		Expect(limiter.When(first)).To(BeNumerically(">", 500*time.Millisecond))
+		Expect(limiter.limiters).To(HaveKey(first))
+	})
+
+	It("should keep a bucket per item", func() {
+		limiter := newPerItemBucketRateLimiter[reconcile.Request](rate.Limit(1), 1)
+		Expect(limiter.When(first)).To(BeZero())
+		Expect(limiter.When(second)).To(BeZero())
+		Expect(limiter.When(first)).To(BeNumerically(">", 0))
+		Expect(limiter.limiters).To(HaveLen(2))
+	})
+
+	It("should drop the bucket of a forgotten item", func() {
+		limiter := newPerItemBucketRateLimiter[reconcile.Request](rate.Limit(1), 1)
+		Expect(limiter.When(first)).To(BeZero())
+		Expect(limiter.When(first)).To(BeNumerically(">", 0))
+		limiter.Forget(first)
+		Expect(limiter.limiters).NotTo(HaveKey(first))
+		Expect(limiter.When(first)).To(BeZero())
+		Expect(limiter.NumRequeues(first)).To(BeZero())
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/clusters"
+)
+
+// remoteMasterWatches keeps a watch on the master ConfigMap of every syncer
+// that pulls its master from a remote cluster. A change of the master
+// enqueues the syncer, just like the sync interval does.
+type remoteMasterWatches struct {
+	mu      sync.Mutex
+	watches map[types.NamespacedName]*remoteMasterWatch
+
+	// events receives the syncers whose master changed
+	events chan event.GenericEvent
+}
+
+// remoteMasterWatch is a running watch on a remote master
+type remoteMasterWatch struct {
+	cluster *clusters.Cluster
+	master  types.NamespacedName
+	cancel  context.CancelFunc
+}
+
+// newRemoteMasterWatches returns an empty set of watches
+func newRemoteMasterWatches() *remoteMasterWatches {
+	return &remoteMasterWatches{
+		watches: map[types.NamespacedName]*remoteMasterWatch{},
+		events:  make(chan event.GenericEvent),
+	}
+}
+
+// source returns the source that enqueues syncers whose remote master changed
+func (w *remoteMasterWatches) source() source.Source {
+	return source.Channel(w.events, &handler.EnqueueRequestForObject{})
+}
+
+// ensure starts watching the master of the syncer in the given cluster. A
+// watch on another master or an outdated connection is replaced.
+func (w *remoteMasterWatches) ensure(
+	configMapSyncer syncv1alpha1.Syncer,
+	cluster *clusters.Cluster,
+	master types.NamespacedName,
+) {
+	key := client.ObjectKeyFromObject(configMapSyncer)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if existing, ok := w.watches[key]; ok {
+		if existing.cluster == cluster && existing.master == master {
+			return
+		}
+		existing.cancel()
+	}
+
+	// The watch outlives the reconcile that started it
+	ctx, cancel := context.WithCancel(context.Background())
+	w.watches[key] = &remoteMasterWatch{cluster: cluster, master: master, cancel: cancel}
+
+	obj := configMapSyncer.DeepCopyObject().(syncv1alpha1.Syncer)
+	go cluster.WatchConfigMap(ctx, master, func() {
+		select {
+		case w.events <- event.GenericEvent{Object: obj}:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// stop ends the watch on the master of the syncer, if there is one
+func (w *remoteMasterWatches) stop(key types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if existing, ok := w.watches[key]; ok {
+		existing.cancel()
+		delete(w.watches, key)
+	}
+}
+
+// watchRemoteMaster starts watching the remote master of the syncer. Masters
+// are not watched when the reconciler runs without a manager.
+func (r *ConfigMapSyncerReconciler) watchRemoteMaster(
+	configMapSyncer syncv1alpha1.Syncer,
+	cluster *clusters.Cluster,
+	master types.NamespacedName,
+) {
+	if r.remoteMasters == nil {
+		return
+	}
+	r.remoteMasters.ensure(configMapSyncer, cluster, master)
+}
+
+// stopWatchingRemoteMaster ends the watch on the remote master of the syncer
+func (r *ConfigMapSyncerReconciler) stopWatchingRemoteMaster(configMapSyncer syncv1alpha1.Syncer) {
+	if r.remoteMasters == nil {
+		return
+	}
+	r.remoteMasters.stop(client.ObjectKeyFromObject(configMapSyncer))
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// StatusReportingInline keeps the per-target statuses in the ConfigMapSyncer status
+	StatusReportingInline = "Inline"
+
+	// StatusReportingReport stores the per-target statuses in ConfigMapSyncReports
+	StatusReportingReport = "Report"
+
+	// StatusReportingAuto switches to reports once the number of targets exceeds the threshold
+	StatusReportingAuto = "Auto"
+
+	// defaultReportThreshold is the number of targets above which Auto mode uses reports
+	defaultReportThreshold = 100
+
+	// defaultMaxFailures is the number of failed targets kept in the status in Report mode
+	defaultMaxFailures = 10
+
+	// reportPageSize is the number of targets stored in a single ConfigMapSyncReport.
+	// It keeps every report well below the etcd object size limit.
+	reportPageSize = 1000
+)
+
+// +kubebuilder:rbac:groups=sync.conf-sync.com,resources=configmapsyncreports,verbs=get;list;watch;create;update;patch;delete
+
+// useReports reports whether the per-target statuses of a sync with the given
+// number of targets are stored in ConfigMapSyncReports
+func useReports(configMapSyncer syncv1alpha1.Syncer, targets int) bool {
+	reporting := configMapSyncer.GetSpec().StatusReporting
+	if reporting == nil {
+		return targets > defaultReportThreshold
+	}
+
+	switch reporting.Mode {
+	case StatusReportingInline:
+		return false
+	case StatusReportingReport:
+		return true
+	default:
+		threshold := int(reporting.Threshold)
+		if threshold == 0 {
+			threshold = defaultReportThreshold
+		}
+		return targets > threshold
+	}
+}
+
+// maxFailures returns the number of failed targets kept in the status in Report mode
+func maxFailures(configMapSyncer syncv1alpha1.Syncer) int {
+	reporting := configMapSyncer.GetSpec().StatusReporting
+	if reporting == nil || reporting.MaxFailures == nil {
+		return defaultMaxFailures
+	}
+	return int(*reporting.MaxFailures)
+}
+
+// reportName returns the name of the ConfigMapSyncReport for the given page
+func reportName(configMapSyncer syncv1alpha1.Syncer, page int) string {
+	if configMapSyncer.GetNamespace() == "" {
+		return fmt.Sprintf("cluster-%s-report-%d", configMapSyncer.GetName(), page)
+	}
+	return fmt.Sprintf("%s-report-%d", configMapSyncer.GetName(), page)
+}
+
+// reportNamespace returns the namespace the ConfigMapSyncReports are stored in.
+// A ClusterConfigMapSyncer has no namespace of its own, its reports are stored
+// next to its master ConfigMap.
+func reportNamespace(configMapSyncer syncv1alpha1.Syncer) string {
+	if namespace := configMapSyncer.GetNamespace(); namespace != "" {
+		return namespace
+	}
+	return configMapSyncer.GetSpec().MasterConfigMap.Namespace
+}
+
+// previousSyncStatuses returns the per-target statuses of the previous sync,
+// reading them from the reports listed in the status if there are any
+func (r *ConfigMapSyncerReconciler) previousSyncStatuses(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+) ([]syncv1alpha1.SyncStatus, error) {
+	if len(configMapSyncer.GetStatus().Reports) == 0 {
+		return configMapSyncer.GetStatus().SyncStatuses, nil
+	}
+
+	var statuses []syncv1alpha1.SyncStatus
+	for _, name := range configMapSyncer.GetStatus().Reports {
+		report := &syncv1alpha1.ConfigMapSyncReport{}
+		key := types.NamespacedName{Name: name, Namespace: reportNamespace(configMapSyncer)}
+		if err := r.Get(ctx, key, report); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			return nil, newTransientError("get ConfigMapSyncReport", err)
+		}
+		statuses = append(statuses, report.SyncStatuses...)
+	}
+	return statuses, nil
+}
+
+// recordSyncStatuses stores the per-target statuses either in the syncer
+// status or, for syncers with many targets, in paginated ConfigMapSyncReports.
+// In the latter case only the most recent failures are kept in the status.
+func (r *ConfigMapSyncerReconciler) recordSyncStatuses(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	statuses []syncv1alpha1.SyncStatus,
+) error {
+	if !useReports(configMapSyncer, len(statuses)) {
+		hadReports := len(configMapSyncer.GetStatus().Reports) > 0
+		configMapSyncer.GetStatus().SyncStatuses = statuses
+		configMapSyncer.GetStatus().RecentFailures = nil
+		configMapSyncer.GetStatus().FailuresOmitted = 0
+		configMapSyncer.GetStatus().Reports = nil
+		if hadReports {
+			return r.deleteReports(ctx, configMapSyncer, 0)
+		}
+		return nil
+	}
+
+	var reports []string
+	for page := 0; page*reportPageSize < len(statuses); page++ {
+		end := min((page+1)*reportPageSize, len(statuses))
+		if err := r.writeReport(ctx, configMapSyncer, page, statuses[page*reportPageSize:end]); err != nil {
+			return err
+		}
+		reports = append(reports, reportName(configMapSyncer, page))
+	}
+	if err := r.deleteReports(ctx, configMapSyncer, len(reports)); err != nil {
+		return err
+	}
+
+	failures, omitted := recentFailures(configMapSyncer.GetStatus().RecentFailures, statuses, maxFailures(configMapSyncer))
+	configMapSyncer.GetStatus().SyncStatuses = nil
+	configMapSyncer.GetStatus().RecentFailures = failures
+	configMapSyncer.GetStatus().FailuresOmitted = int32(omitted)
+	configMapSyncer.GetStatus().Reports = reports
+	return nil
+}
+
+// recentFailures picks up to limit failed statuses and returns them with the
+// number of failures left out. Targets that started failing since the previous
+// sync come first, sorted by target, followed by the ones that were already
+// reported in their previous order, so that the choice does not depend on the
+// order targets were synced in.
+func recentFailures(previous, statuses []syncv1alpha1.SyncStatus, limit int) ([]syncv1alpha1.SyncStatus, int) {
+	reported := make(map[targetKey]int, len(previous))
+	for i, status := range previous {
+		reported[targetKeyOf(status)] = i
+	}
+
+	var failures []syncv1alpha1.SyncStatus
+	for _, status := range statuses {
+		if status.Status == SyncStatusFailed || status.Status == SyncStatusForbidden {
+			failures = append(failures, status)
+		}
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		a, b := targetKeyOf(failures[i]), targetKeyOf(failures[j])
+		ai, aReported := reported[a]
+		bi, bReported := reported[b]
+		switch {
+		case aReported != bReported:
+			return bReported
+		case aReported:
+			return ai < bi
+		}
+		return fmt.Sprint(a) < fmt.Sprint(b)
+	})
+
+	if len(failures) <= limit {
+		return failures, 0
+	}
+	return failures[:limit], len(failures) - limit
+}
+
+// writeReport creates or updates a single page of the report. Timestamps alone
+// do not cause an update.
+func (r *ConfigMapSyncerReconciler) writeReport(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	page int,
+	statuses []syncv1alpha1.SyncStatus,
+) error {
+	report := &syncv1alpha1.ConfigMapSyncReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reportName(configMapSyncer, page),
+			Namespace: reportNamespace(configMapSyncer),
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, report, func() error {
+		report.Spec.SyncerKind = kindOf(configMapSyncer)
+		report.Spec.SyncerName = configMapSyncer.GetName()
+		report.Spec.Page = int32(page)
+		if !syncStatusesEqualIgnoringTimestamps(report.SyncStatuses, statuses) {
+			report.SyncStatuses = statuses
+		}
+		return controllerutil.SetControllerReference(configMapSyncer, report, r.Scheme)
+	})
+	if err != nil {
+		return newTransientError("write ConfigMapSyncReport", err)
+	}
+	return nil
+}
+
+// deleteReports deletes the reports of the syncer from the given page on
+func (r *ConfigMapSyncerReconciler) deleteReports(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	fromPage int,
+) error {
+	reports := &syncv1alpha1.ConfigMapSyncReportList{}
+	if err := r.List(ctx, reports, client.InNamespace(reportNamespace(configMapSyncer))); err != nil {
+		return newTransientError("list ConfigMapSyncReports", err)
+	}
+
+	for i := range reports.Items {
+		report := reports.Items[i].Spec
+		if report.SyncerKind != kindOf(configMapSyncer) || report.SyncerName != configMapSyncer.GetName() ||
+			int(report.Page) < fromPage {
+			continue
+		}
+		if err := client.IgnoreNotFound(r.Delete(ctx, &reports.Items[i])); err != nil {
+			return newTransientError("delete ConfigMapSyncReport", err)
+		}
+	}
+	return nil
+}
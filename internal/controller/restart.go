@@ -0,0 +1,229 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// RestartAnnotationPrefix is the prefix of the pod template annotations that
+	// hold the content hash of the target a workload was last restarted for
+	RestartAnnotationPrefix = "restart.configmapsyncer.conf-sync.com/"
+
+	// EventReasonWorkloadRestarted is the event reason when a workload was restarted after its target changed
+	EventReasonWorkloadRestarted = "WorkloadRestarted"
+
+	// EventReasonRestartFailed is the event reason when a workload could not be restarted
+	EventReasonRestartFailed = "RestartFailed"
+
+	// restartFailedMessage starts the status message of targets whose workloads could not be restarted
+	restartFailedMessage = "Failed to restart workloads"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;patch
+
+// workload is a Deployment, StatefulSet or DaemonSet together with its pod template
+type workload struct {
+	kind     string
+	resource string
+	object   client.Object
+	template *corev1.PodTemplateSpec
+}
+
+// listWorkloads lists the workloads in the namespace that match the selector
+func listWorkloads(ctx context.Context, reader client.Reader, namespace string, selector labels.Selector) ([]workload, error) {
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}}
+	var workloads []workload
+
+	deployments := &appsv1.DeploymentList{}
+	if err := reader.List(ctx, deployments, opts...); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		workloads = append(workloads, workload{"Deployment", "deployments", deployment, &deployment.Spec.Template})
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := reader.List(ctx, statefulSets, opts...); err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		workloads = append(workloads, workload{"StatefulSet", "statefulsets", statefulSet, &statefulSet.Spec.Template})
+	}
+
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := reader.List(ctx, daemonSets, opts...); err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		daemonSet := &daemonSets.Items[i]
+		workloads = append(workloads, workload{"DaemonSet", "daemonsets", daemonSet, &daemonSet.Spec.Template})
+	}
+
+	return workloads, nil
+}
+
+// references reports whether the pod spec references the ConfigMap or Secret
+// through a volume, a projected volume, env or envFrom
+func references(spec *corev1.PodSpec, kind, name string) bool {
+	for _, volume := range spec.Volumes {
+		switch {
+		case kind == KindConfigMap && volume.ConfigMap != nil && volume.ConfigMap.Name == name:
+			return true
+		case kind == KindSecret && volume.Secret != nil && volume.Secret.SecretName == name:
+			return true
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if kind == KindConfigMap && source.ConfigMap != nil && source.ConfigMap.Name == name ||
+					kind == KindSecret && source.Secret != nil && source.Secret.Name == name {
+					return true
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if kind == KindConfigMap && envFrom.ConfigMapRef != nil && envFrom.ConfigMapRef.Name == name ||
+				kind == KindSecret && envFrom.SecretRef != nil && envFrom.SecretRef.Name == name {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if kind == KindConfigMap && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name == name ||
+				kind == KindSecret && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// failedRestarts returns the targets whose workloads could not be restarted
+// by the previous sync. Their restart is retried although they are in sync.
+func failedRestarts(status *syncv1alpha1.ConfigMapSyncerStatus) map[targetKey]bool {
+	failed := map[targetKey]bool{}
+	for _, statuses := range [][]syncv1alpha1.SyncStatus{status.SyncStatuses, status.RecentFailures} {
+		for _, syncStatus := range statuses {
+			if syncStatus.Status == SyncStatusFailed && strings.HasPrefix(syncStatus.Message, restartFailedMessage) {
+				failed[targetKeyOf(syncStatus)] = true
+			}
+		}
+	}
+	return failed
+}
+
+// restartAnnotation returns the pod template annotation holding the content
+// hash of the target. Names that do not fit into an annotation name are
+// shortened and suffixed with a hash of the full name.
+func restartAnnotation(kind, name string) string {
+	const maxLength = 63
+	key := strings.ToLower(kind) + "." + name
+	if len(key) > maxLength {
+		sum := sha256.Sum256([]byte(key))
+		suffix := "-" + hex.EncodeToString(sum[:])[:10]
+		key = key[:maxLength-len(suffix)] + suffix
+	}
+	return RestartAnnotationPrefix + key
+}
+
+// restartWorkloads restarts the selected workloads in the namespace of the
+// target whose pod template references it, by setting the content hash of the
+// target in a pod template annotation. Workloads that were never restarted for
+// the target are only restarted when this sync wrote the target, otherwise
+// their pods already started with the current content. Workloads restarted for
+// older content are caught up, e.g. after a failed restart. It returns the
+// number of restarted workloads.
+func (r *ConfigMapSyncerReconciler) restartWorkloads(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	cluster *targetCluster,
+	authorizer *tenantAuthorizer,
+	selector labels.Selector,
+	kind string,
+	target types.NamespacedName,
+	contentHash string,
+	written bool,
+) (int, error) {
+	logger := log.FromContext(ctx).WithValues("cluster", cluster.displayName())
+
+	workloads, err := listWorkloads(ctx, cluster.reader, target.Namespace, selector)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list workloads: %w", err)
+	}
+
+	annotation := restartAnnotation(kind, target.Name)
+	restarted := 0
+	var errs []error
+	for _, w := range workloads {
+		if !references(&w.template.Spec, kind, target.Name) {
+			continue
+		}
+		current, ok := w.template.Annotations[annotation]
+		if current == contentHash || !ok && !written {
+			continue
+		}
+
+		key := client.ObjectKeyFromObject(w.object)
+		reason, err := authorizer.authorizeResource(ctx, appsv1.GroupName, w.resource, w.kind, key, "patch")
+		if err == nil && reason != "" {
+			err = errors.New(reason)
+		}
+		if err == nil {
+			original := w.object.DeepCopyObject().(client.Object)
+			if w.template.Annotations == nil {
+				w.template.Annotations = make(map[string]string)
+			}
+			w.template.Annotations[annotation] = contentHash
+			err = cluster.client.Patch(ctx, w.object, client.MergeFrom(original))
+		}
+		if err != nil {
+			logger.Error(err, "Failed to restart workload", "kind", w.kind, "namespace", key.Namespace, "name", key.Name)
+			r.event(configMapSyncer, corev1.EventTypeWarning, EventReasonRestartFailed,
+				"Failed to restart %s %s after %s %s changed: %v", w.kind, key, kind, target, err)
+			errs = append(errs, fmt.Errorf("%s %s: %w", w.kind, key.Name, err))
+			continue
+		}
+
+		logger.Info("Restarted workload", "kind", w.kind, "namespace", key.Namespace, "name", key.Name)
+		r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonWorkloadRestarted,
+			"Restarted %s %s after %s %s changed", w.kind, key, kind, target)
+		restarted++
+	}
+	return restarted, errors.Join(errs...)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Restarting workloads", func() {
+	const (
+		resourceName    = "restart-syncer"
+		masterName      = "restart-config"
+		targetNamespace = "restart-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+
+	deployment := func(name string, spec corev1.PodSpec) *appsv1.Deployment {
+		labels := map[string]string{"app": name}
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: targetNamespace},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       spec,
+				},
+			},
+		}
+	}
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+	})
+
+	It("should restart workloads referencing a target after it changed", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		master := createMaster(ctx, masterName, map[string]string{"color": "blue"})
+		DeferCleanup(func() { Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, master))).To(Succeed()) })
+
+		consumer := deployment("consumer", corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Image: "busybox",
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: masterName}},
+			}},
+		}}})
+		bystander := deployment("bystander", corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "busybox"}}})
+		for _, d := range []*appsv1.Deployment{consumer, bystander} {
+			Expect(k8sClient.Create(ctx, d)).To(Succeed())
+			DeferCleanup(func() { Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, d))).To(Succeed()) })
+		}
+
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				RestartWorkloads: &syncv1alpha1.RestartWorkloads{},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+		controllerReconciler := newClusterReconciler()
+		reconcileAndGetHash := func() string {
+			for range 2 {
+				reconcileOnce(ctx, controllerReconciler, resource)
+			}
+			target := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, target)).To(Succeed())
+			return target.Annotations[ContentHashAnnotation]
+		}
+		annotation := restartAnnotation(KindConfigMap, masterName)
+
+		hash := reconcileAndGetHash()
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(consumer), consumer)).To(Succeed())
+		Expect(consumer.Spec.Template.Annotations).To(HaveKeyWithValue(annotation, hash))
+
+		master.Data["color"] = "green"
+		Expect(k8sClient.Update(ctx, master)).To(Succeed())
+		newHash := reconcileAndGetHash()
+		Expect(newHash).NotTo(Equal(hash))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(consumer), consumer)).To(Succeed())
+		Expect(consumer.Spec.Template.Annotations).To(HaveKeyWithValue(annotation, newHash))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(bystander), bystander)).To(Succeed())
+		Expect(bystander.Spec.Template.Annotations).NotTo(HaveKey(annotation))
+	})
+
+})
+
+var _ = Describe("restartAnnotation", func() {
+	It("should keep annotation names of long targets valid", func() {
+		name := "a-very-long-target-configmap-name-that-does-not-fit-into-an-annotation-name"
+		annotation := restartAnnotation(KindConfigMap, name)
+		Expect(len(annotation) - len(RestartAnnotationPrefix)).To(BeNumerically("<=", 63))
+		Expect(annotation).NotTo(Equal(restartAnnotation(KindConfigMap, name+"-other")))
+	})
+})
+
+var _ = Describe("failedRestarts", func() {
+	It("should only retry targets whose restart failed", func() {
+		status := &syncv1alpha1.ConfigMapSyncerStatus{
+			SyncStatuses: []syncv1alpha1.SyncStatus{
+				{Namespace: "app1", ConfigMapName: "config", Status: SyncStatusSynced},
+				{Namespace: "app2", ConfigMapName: "config", Status: SyncStatusFailed, Message: "Failed to update ConfigMap: conflict"},
+			},
+			RecentFailures: []syncv1alpha1.SyncStatus{
+				{Namespace: "app3", ConfigMapName: "config", Status: SyncStatusFailed, Message: restartFailedMessage + ": forbidden"},
+			},
+		}
+		Expect(failedRestarts(status)).To(Equal(map[targetKey]bool{
+			{kind: KindConfigMap, NamespacedName: types.NamespacedName{Namespace: "app3", Name: "config"}}: true,
+		}))
+	})
+})
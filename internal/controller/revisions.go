@@ -0,0 +1,318 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// RevisionOwnerLabel is the label key holding the UID of the syncer a ControllerRevision belongs to
+	RevisionOwnerLabel = "configmapsyncer.conf-sync.com/owner-uid"
+
+	// RevisionHashAnnotation is the annotation key for the content hash of a ControllerRevision
+	RevisionHashAnnotation = "configmapsyncer.conf-sync.com/revision-hash"
+
+	// defaultRevisionHistoryLimit is the number of revisions kept when spec.revisionHistoryLimit is not set
+	defaultRevisionHistoryLimit = 10
+)
+
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;create;update;delete
+
+// revisionHistoryLimit returns the number of revisions kept in the history
+func revisionHistoryLimit(configMapSyncer syncv1alpha1.Syncer) int {
+	if limit := configMapSyncer.GetSpec().RevisionHistoryLimit; limit != nil && *limit > 0 {
+		return int(*limit)
+	}
+	return defaultRevisionHistoryLimit
+}
+
+// keepsHistory reports whether the master content is recorded in
+// ControllerRevisions. Secret masters are not, their data would end up in
+// plain text in objects that are not Secrets.
+func keepsHistory(configMapSyncer syncv1alpha1.Syncer) bool {
+	spec := configMapSyncer.GetSpec()
+	return spec.Source != nil || spec.MasterConfigMap.Kind != KindSecret
+}
+
+// revisionName returns the name of the ControllerRevision holding the content
+// with the given hash. Syncer names that do not fit into an object name with
+// the hash are shortened and suffixed with a hash of the full name.
+func revisionName(configMapSyncer syncv1alpha1.Syncer, hash string) string {
+	const maxLength = 253
+	prefix := configMapSyncer.GetName()
+	if configMapSyncer.GetNamespace() == "" {
+		prefix = "cluster-" + prefix
+	}
+	suffix := "-" + hash[:10]
+	if len(prefix)+len(suffix) > maxLength {
+		sum := sha256.Sum256([]byte(prefix))
+		prefix = strings.TrimRight(prefix[:maxLength-2*len(suffix)], ".-") + "-" + hex.EncodeToString(sum[:])[:10]
+	}
+	return prefix + suffix
+}
+
+// listRevisions returns the ControllerRevisions of the syncer, newest first.
+// They are stored next to the reports and read from the API server, the
+// cache would otherwise hold every ControllerRevision of the cluster.
+func (r *ConfigMapSyncerReconciler) listRevisions(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+) ([]appsv1.ControllerRevision, error) {
+	revisions := &appsv1.ControllerRevisionList{}
+	if err := r.apiReader().List(ctx, revisions,
+		client.InNamespace(reportNamespace(configMapSyncer)),
+		client.MatchingLabels{RevisionOwnerLabel: string(configMapSyncer.GetUID())},
+	); err != nil {
+		return nil, newTransientError("list ControllerRevisions", err)
+	}
+	sort.Slice(revisions.Items, func(i, j int) bool {
+		return revisions.Items[i].Revision > revisions.Items[j].Revision
+	})
+	return revisions.Items, nil
+}
+
+// recordRevision adds the master content to the revision history unless it
+// is the newest revision already. Content seen before becomes the newest
+// revision again. Revisions beyond the history limit are deleted, except for
+// the pinned one, and the history is reported in the status.
+func (r *ConfigMapSyncerReconciler) recordRevision(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	masterConfigMap *corev1.ConfigMap,
+) error {
+	revisions, err := r.listRevisions(ctx, configMapSyncer)
+	if err != nil {
+		return err
+	}
+
+	hash := computeContentHash(masterConfigMap, configMapSyncer)
+	var next int64 = 1
+	if len(revisions) > 0 {
+		next = revisions[0].Revision + 1
+	}
+	index := -1
+	for i := range revisions {
+		if revisions[i].Annotations[RevisionHashAnnotation] == hash {
+			index = i
+			break
+		}
+	}
+
+	switch {
+	case index == 0:
+	case index > 0:
+		revision := revisions[index]
+		revision.Revision = next
+		if err := r.Update(ctx, &revision); err != nil {
+			return newTransientError("update ControllerRevision", err)
+		}
+		revisions = append(append([]appsv1.ControllerRevision{revision}, revisions[:index]...), revisions[index+1:]...)
+	default:
+		revision, err := r.createRevision(ctx, configMapSyncer, masterConfigMap, hash, next)
+		if err != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("Recorded master revision", "revision", next, "hash", hash)
+		revisions = append([]appsv1.ControllerRevision{*revision}, revisions...)
+	}
+
+	limit := revisionHistoryLimit(configMapSyncer)
+	kept := revisions[:0]
+	for i := range revisions {
+		hash := revisions[i].Annotations[RevisionHashAnnotation]
+		if len(kept) < limit || pinnedBy(configMapSyncer, hash) || hash == configMapSyncer.GetStatus().CurrentRevision {
+			kept = append(kept, revisions[i])
+			continue
+		}
+		if err := client.IgnoreNotFound(r.Delete(ctx, &revisions[i])); err != nil {
+			return newTransientError("delete ControllerRevision", err)
+		}
+	}
+
+	history := make([]syncv1alpha1.RevisionStatus, 0, len(kept))
+	for _, revision := range kept {
+		history = append(history, syncv1alpha1.RevisionStatus{
+			Revision:     revision.Revision,
+			Hash:         revision.Annotations[RevisionHashAnnotation],
+			Name:         revision.Name,
+			CreationTime: revision.CreationTimestamp,
+		})
+	}
+	configMapSyncer.GetStatus().Revisions = history
+	return nil
+}
+
+// createRevision stores the data of the master in a new ControllerRevision owned by the syncer
+func (r *ConfigMapSyncerReconciler) createRevision(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	masterConfigMap *corev1.ConfigMap,
+	hash string,
+	number int64,
+) (*appsv1.ControllerRevision, error) {
+	revision := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        revisionName(configMapSyncer, hash),
+			Namespace:   reportNamespace(configMapSyncer),
+			Labels:      map[string]string{RevisionOwnerLabel: string(configMapSyncer.GetUID())},
+			Annotations: map[string]string{RevisionHashAnnotation: hash},
+		},
+		Data: runtime.RawExtension{Object: &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			Data:       masterConfigMap.Data,
+			BinaryData: masterConfigMap.BinaryData,
+		}},
+		Revision: number,
+	}
+	if err := controllerutil.SetControllerReference(configMapSyncer, revision, r.Scheme); err != nil {
+		return nil, newTransientError("set owner of ControllerRevision", err)
+	}
+	if err := r.Create(ctx, revision); err != nil {
+		return nil, newTransientError("create ControllerRevision", err)
+	}
+	return revision, nil
+}
+
+// pinnedBy reports whether spec.pinnedRevision selects the revision with the given hash
+func pinnedBy(configMapSyncer syncv1alpha1.Syncer, hash string) bool {
+	pinned := configMapSyncer.GetSpec().PinnedRevision
+	return pinned != "" && strings.HasPrefix(hash, pinned)
+}
+
+// pinnedMaster returns the master to propagate: the master itself, or a copy
+// holding the content of the pinned revision. It must be called after
+// recordRevision, a pinned revision missing from the history is a
+// ValidationError.
+func (r *ConfigMapSyncerReconciler) pinnedMaster(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	masterConfigMap *corev1.ConfigMap,
+) (*corev1.ConfigMap, error) {
+	pinned := configMapSyncer.GetSpec().PinnedRevision
+	if pinned == "" {
+		return masterConfigMap, nil
+	}
+	if !keepsHistory(configMapSyncer) {
+		return nil, newValidationError("spec.pinnedRevision", fmt.Errorf("secret masters have no revision history to pin"))
+	}
+
+	var matches []syncv1alpha1.RevisionStatus
+	for _, revision := range configMapSyncer.GetStatus().Revisions {
+		if pinnedBy(configMapSyncer, revision.Hash) {
+			matches = append(matches, revision)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, newValidationError("spec.pinnedRevision", fmt.Errorf("revision %s is not in the history", pinned))
+	case 1:
+	default:
+		return nil, newValidationError("spec.pinnedRevision", fmt.Errorf("revision %s is ambiguous", pinned))
+	}
+
+	content, err := r.revisionContent(ctx, configMapSyncer, matches[0].Name)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, newValidationError("spec.pinnedRevision", fmt.Errorf("revision %s is not in the history", pinned))
+	}
+	return withContent(masterConfigMap, content), nil
+}
+
+// revisionContent reads the content of a ControllerRevision of the syncer. It
+// returns nil if the revision is gone.
+func (r *ConfigMapSyncerReconciler) revisionContent(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	name string,
+) (*corev1.ConfigMap, error) {
+	revision := &appsv1.ControllerRevision{}
+	key := types.NamespacedName{Name: name, Namespace: reportNamespace(configMapSyncer)}
+	if err := r.apiReader().Get(ctx, key, revision); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, newTransientError("get ControllerRevision", err)
+	}
+	content := &corev1.ConfigMap{}
+	if err := json.Unmarshal(revision.Data.Raw, content); err != nil {
+		return nil, fmt.Errorf("read ControllerRevision %s: %w", name, err)
+	}
+	return content, nil
+}
+
+// withContent returns a copy of the master holding the data of the content
+func withContent(masterConfigMap, content *corev1.ConfigMap) *corev1.ConfigMap {
+	copied := masterConfigMap.DeepCopy()
+	copied.Data = content.Data
+	copied.BinaryData = content.BinaryData
+	return copied
+}
+
+// revisionToPropagate records the master in the revision history, unless
+// dry-running, and returns the master to propagate, reporting its content hash
+// in the status. It returns nil if there is nothing to propagate, as long as
+// the first content of a syncer with manual approval is not approved, or while
+// a new content of a Secret master waits for approval.
+func (r *ConfigMapSyncerReconciler) revisionToPropagate(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	masterConfigMap *corev1.ConfigMap,
+) (*corev1.ConfigMap, error) {
+	switch {
+	case !keepsHistory(configMapSyncer):
+		configMapSyncer.GetStatus().Revisions = nil
+	case !r.dryRun(configMapSyncer):
+		// A dry run leaves the revision history as it is
+		if err := r.recordRevision(ctx, configMapSyncer, masterConfigMap); err != nil {
+			return nil, err
+		}
+	}
+	propagated, err := r.pinnedMaster(ctx, configMapSyncer, masterConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	if configMapSyncer.GetSpec().PinnedRevision == "" {
+		if propagated, err = r.approvedMaster(ctx, configMapSyncer, masterConfigMap); err != nil || propagated == nil {
+			return nil, err
+		}
+	} else {
+		configMapSyncer.GetStatus().PendingRevision = nil
+	}
+	configMapSyncer.GetStatus().CurrentRevision = computeContentHash(propagated, configMapSyncer)
+	return propagated, nil
+}
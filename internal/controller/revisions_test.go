@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Revision history", func() {
+	const (
+		resourceName    = "revision-syncer"
+		masterName      = "revision-config"
+		targetNamespace = "revision-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+	masterKey := types.NamespacedName{Name: masterName, Namespace: "default"}
+	targetKey := types.NamespacedName{Name: masterName, Namespace: targetNamespace}
+
+	BeforeEach(func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		createMaster(ctx, masterName, map[string]string{"color": "blue"})
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+		for _, key := range []types.NamespacedName{masterKey, targetKey} {
+			target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, target))).To(Succeed())
+		}
+	})
+
+	It("should record revisions and roll back to a pinned one", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(resource.Status.Revisions).To(HaveLen(1))
+		first := resource.Status.Revisions[0].Hash
+
+		master := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, masterKey, master)).To(Succeed())
+		master.Data["color"] = "green"
+		Expect(k8sClient.Update(ctx, master)).To(Succeed())
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(resource.Status.Revisions).To(HaveLen(2))
+		Expect(resource.Status.Revisions[0].Revision).To(Equal(int64(2)))
+		Expect(resource.Status.CurrentRevision).To(Equal(resource.Status.Revisions[0].Hash))
+
+		resource.Spec.PinnedRevision = first[:12]
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(resource.Status.CurrentRevision).To(Equal(first))
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, targetKey, target)).To(Succeed())
+		Expect(target.Data).To(HaveKeyWithValue("color", "blue"))
+	})
+
+	It("should delete revisions beyond the history limit but keep the pinned one", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:      syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces:     []string{targetNamespace},
+				RevisionHistoryLimit: ptr.To[int32](2),
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+		setColor := func(color string) {
+			master := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, masterKey, master)).To(Succeed())
+			master.Data["color"] = color
+			Expect(k8sClient.Update(ctx, master)).To(Succeed())
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		reconcileOnce(ctx, controllerReconciler, resource)
+		first := resource.Status.CurrentRevision
+		setColor("green")
+		resource.Spec.PinnedRevision = first[:12]
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		reconcileOnce(ctx, controllerReconciler, resource)
+		setColor("red")
+		setColor("yellow")
+
+		Expect(resource.Status.CurrentRevision).To(Equal(first))
+		Expect(resource.Status.Revisions).To(HaveLen(3))
+		Expect(resource.Status.Revisions[0].Revision).To(Equal(int64(4)))
+		Expect(resource.Status.Revisions[1].Revision).To(Equal(int64(3)))
+		Expect(resource.Status.Revisions[2].Hash).To(Equal(first))
+		revisions := &appsv1.ControllerRevisionList{}
+		Expect(k8sClient.List(ctx, revisions, client.InNamespace("default"),
+			client.MatchingLabels{RevisionOwnerLabel: string(resource.UID)})).To(Succeed())
+		Expect(revisions.Items).To(HaveLen(3))
+	})
+
+	It("should refuse pins that match no or several revisions", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec:       syncv1alpha1.ConfigMapSyncerSpec{PinnedRevision: "abcdef0"},
+			Status: syncv1alpha1.ConfigMapSyncerStatus{Revisions: []syncv1alpha1.RevisionStatus{
+				{Revision: 2, Hash: "abcdef01" + strings.Repeat("0", 56)},
+				{Revision: 1, Hash: "abcdef02" + strings.Repeat("0", 56)},
+			}},
+		}
+		reconciler := &ConfigMapSyncerReconciler{}
+
+		_, err := reconciler.pinnedMaster(ctx, resource, &corev1.ConfigMap{})
+		Expect(IsValidationError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("ambiguous")))
+
+		resource.Spec.PinnedRevision = "1234567"
+		_, err = reconciler.pinnedMaster(ctx, resource, &corev1.ConfigMap{})
+		Expect(IsValidationError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("not in the history")))
+	})
+
+	It("should shorten revision names of long syncer names", func() {
+		hash := strings.Repeat("f", 64)
+		short := &syncv1alpha1.ClusterConfigMapSyncer{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+		Expect(revisionName(short, hash)).To(Equal("cluster-app-ffffffffff"))
+
+		long := &syncv1alpha1.ClusterConfigMapSyncer{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 253)}}
+		other := &syncv1alpha1.ClusterConfigMapSyncer{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 252)}}
+		Expect(revisionName(long, hash)).To(HaveLen(253))
+		Expect(revisionName(long, hash)).To(HaveSuffix("-ffffffffff"))
+		Expect(revisionName(long, hash)).NotTo(Equal(revisionName(other, hash)))
+	})
+})
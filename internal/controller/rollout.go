@@ -0,0 +1,515 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// ConditionTypeRolloutHalted is the type for the condition reporting a rollout halted by an unhealthy canary
+	ConditionTypeRolloutHalted = "RolloutHalted"
+
+	// ConditionReasonRolloutInProgress is the reason while a rollout waits for a wave to become healthy or for the pause
+	ConditionReasonRolloutInProgress = "RolloutInProgress"
+
+	// ConditionReasonRolloutPaused is the reason while a rollout is paused through spec.rolloutStrategy.paused
+	ConditionReasonRolloutPaused = "RolloutPaused"
+
+	// ConditionReasonCanaryUnhealthy is the reason when a canary workload became unhealthy and the rollout was halted
+	ConditionReasonCanaryUnhealthy = "CanaryUnhealthy"
+
+	// ConditionReasonRolloutNotHalted is the reason while the rollout is not halted
+	ConditionReasonRolloutNotHalted = "RolloutNotHalted"
+
+	// EventReasonRolloutWaveStarted is the event reason when the next wave of a rollout starts
+	EventReasonRolloutWaveStarted = "RolloutWaveStarted"
+
+	// EventReasonRolloutHalted is the event reason when a rollout was halted by an unhealthy canary
+	EventReasonRolloutHalted = "RolloutHalted"
+
+	// RolloutHealthCheckWorkloadsReady waits for the workloads restarted in a wave to be ready
+	RolloutHealthCheckWorkloadsReady = "WorkloadsReady"
+
+	// RolloutHealthCheckNone only waits for the targets of a wave to be in sync
+	RolloutHealthCheckNone = "None"
+
+	// rolloutRecheckInterval is how often the health of a wave is checked
+	rolloutRecheckInterval = 10 * time.Second
+)
+
+// mergeStrategyOf returns the merge strategy of the syncer, Merge unless Replace is set
+func mergeStrategyOf(configMapSyncer syncv1alpha1.Syncer) string {
+	if configMapSyncer.GetSpec().MergeStrategy == MergeStrategyReplace {
+		return MergeStrategyReplace
+	}
+	return MergeStrategyMerge
+}
+
+// rolloutWave is a wave of a rollout, either the canaries or a wave of the rollout strategy
+type rolloutWave struct {
+	name  string
+	field string
+	pause time.Duration
+
+	// canaries lists the namespaces of the canary wave
+	canaries []string
+
+	// spec is the wave of the rollout strategy
+	spec *syncv1alpha1.RolloutWave
+}
+
+// rolloutWaves returns the waves of the rollouts of the syncer, not counting
+// the final wave of the namespaces no wave selects. It returns nil when
+// changes are propagated to all targets at once.
+func rolloutWaves(configMapSyncer syncv1alpha1.Syncer) []rolloutWave {
+	spec := configMapSyncer.GetSpec()
+	var waves []rolloutWave
+	if spec.Canary != nil {
+		waves = append(waves, rolloutWave{
+			name:     "canary",
+			field:    "spec.canary",
+			pause:    time.Duration(spec.Canary.SoakSeconds) * time.Second,
+			canaries: spec.Canary.Namespaces,
+		})
+	}
+	if strategy := spec.RolloutStrategy; strategy != nil {
+		for i := range strategy.Waves {
+			name := strategy.Waves[i].Name
+			if name == "" {
+				name = fmt.Sprintf("wave %d", i+1)
+			}
+			waves = append(waves, rolloutWave{
+				name:  name,
+				field: fmt.Sprintf("spec.rolloutStrategy.waves[%d]", i),
+				pause: time.Duration(strategy.PauseSeconds) * time.Second,
+				spec:  &strategy.Waves[i],
+			})
+		}
+	}
+	return waves
+}
+
+// waveName returns the name of the wave for messages
+func waveName(waves []rolloutWave, wave int) string {
+	if wave >= len(waves) {
+		return "remaining namespaces"
+	}
+	return waves[wave].name
+}
+
+// beginRollout records the rollout of the master content in the status. A
+// new content starts over with the first wave.
+func beginRollout(configMapSyncer syncv1alpha1.Syncer, masterConfigMap *corev1.ConfigMap) {
+	waves := rolloutWaves(configMapSyncer)
+	status := configMapSyncer.GetStatus()
+	if waves == nil {
+		status.Rollout = nil
+		return
+	}
+
+	revision := computeContentHash(masterConfigMap, configMapSyncer)
+	if status.Rollout == nil || status.Rollout.Revision != revision {
+		status.Rollout = &syncv1alpha1.RolloutStatus{Revision: revision}
+	}
+	status.Rollout.TotalWaves = int32(len(waves) + 1)
+	status.Rollout.CurrentWave = min(status.Rollout.CurrentWave, status.Rollout.TotalWaves)
+}
+
+// rolloutInProgress returns the rollout of the syncer unless there is none or it is complete
+func rolloutInProgress(configMapSyncer syncv1alpha1.Syncer) *syncv1alpha1.RolloutStatus {
+	rollout := configMapSyncer.GetStatus().Rollout
+	if rolloutWaves(configMapSyncer) == nil || rollout == nil || rollout.CurrentWave >= rollout.TotalWaves {
+		return nil
+	}
+	return rollout
+}
+
+// waveAssignment maps the target namespaces of a cluster to the waves of a rollout
+type waveAssignment struct {
+	waves       map[string]int
+	currentWave int
+}
+
+// held reports whether the namespace belongs to a wave after the current one and returns its wave
+func (w *waveAssignment) held(namespace string) (int, bool) {
+	if w == nil {
+		return 0, false
+	}
+	wave, ok := w.waves[namespace]
+	return wave, ok && wave > w.currentWave
+}
+
+// current reports whether the namespace belongs to the current wave
+func (w *waveAssignment) current(namespace string) bool {
+	if w == nil {
+		return false
+	}
+	wave, ok := w.waves[namespace]
+	return ok && wave == w.currentWave
+}
+
+// assignWaves assigns the target namespaces of the cluster to the waves of
+// the rollout in progress. Every namespace goes to the first wave selecting
+// it, percentages count the namespaces in alphabetical order and namespaces
+// no wave selects go to the final wave. It returns nil if no rollout is in
+// progress.
+func (r *ConfigMapSyncerReconciler) assignWaves(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	cluster *targetCluster,
+	targetNamespaces []string,
+) (*waveAssignment, error) {
+	rollout := rolloutInProgress(configMapSyncer)
+	if rollout == nil {
+		return nil, nil
+	}
+	waves := rolloutWaves(configMapSyncer)
+
+	namespaceList := namespaceMetadataList()
+	if err := cluster.client.List(ctx, namespaceList); err != nil {
+		return nil, newTransientError("list namespaces", err)
+	}
+	namespaceLabels := make(map[string]labels.Set, len(namespaceList.Items))
+	for _, ns := range namespaceList.Items {
+		namespaceLabels[ns.Name] = ns.Labels
+	}
+	namespaces := make([]string, 0, len(targetNamespaces))
+	for _, namespace := range targetNamespaces {
+		if !holdsMaster(cluster, masterOrigin(configMapSyncer), namespace, configMapSyncer.GetSpec().MasterConfigMap.Namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	assignment := &waveAssignment{waves: make(map[string]int, len(namespaces)), currentWave: int(rollout.CurrentWave)}
+	for i, wave := range waves {
+		switch {
+		case wave.canaries != nil:
+			for _, namespace := range namespaces {
+				if _, ok := assignment.waves[namespace]; !ok && slices.Contains(wave.canaries, namespace) {
+					assignment.waves[namespace] = i
+				}
+			}
+		case wave.spec.NamespaceSelector != nil:
+			selector, err := metav1.LabelSelectorAsSelector(wave.spec.NamespaceSelector)
+			if err != nil {
+				return nil, newValidationError(wave.field+".namespaceSelector", err)
+			}
+			for _, namespace := range namespaces {
+				if _, ok := assignment.waves[namespace]; !ok && selector.Matches(namespaceLabels[namespace]) {
+					assignment.waves[namespace] = i
+				}
+			}
+		case wave.spec.Percent != nil:
+			count := (len(namespaces)*int(*wave.spec.Percent) + 99) / 100
+			for _, namespace := range namespaces {
+				if len(assignment.waves) >= count {
+					break
+				}
+				if _, ok := assignment.waves[namespace]; !ok {
+					assignment.waves[namespace] = i
+				}
+			}
+		}
+	}
+	for _, namespace := range namespaces {
+		if _, ok := assignment.waves[namespace]; !ok {
+			assignment.waves[namespace] = len(waves)
+		}
+	}
+	return assignment, nil
+}
+
+// advanceRollout moves the rollout on to the next wave once the current wave
+// is healthy and the pause has passed, or halts it when a canary became
+// unhealthy. It returns when the rollout should be looked at again, zero if
+// it does not need to be.
+func (r *ConfigMapSyncerReconciler) advanceRollout(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	result *syncResult,
+	clusters map[string]*targetCluster,
+) (time.Duration, error) {
+	rollout := rolloutInProgress(configMapSyncer)
+	if rollout == nil || rollout.Halted {
+		return 0, nil
+	}
+	waves := rolloutWaves(configMapSyncer)
+	var wave *rolloutWave
+	if int(rollout.CurrentWave) < len(waves) {
+		wave = &waves[rollout.CurrentWave]
+	}
+
+	healthy, halt, err := r.waveHealthy(ctx, configMapSyncer, wave, result, clusters)
+	if err != nil {
+		return 0, err
+	}
+	if halt != "" {
+		log.FromContext(ctx).Info("Halting rollout", "revision", rollout.Revision, "reason", halt)
+		rollout.Halted = true
+		rollout.Message = halt
+		r.event(configMapSyncer, corev1.EventTypeWarning, EventReasonRolloutHalted,
+			"Halted rollout of revision %s: %s", shortRevision(rollout.Revision), halt)
+		return 0, nil
+	}
+	if !healthy {
+		rollout.WaveHealthyTime = nil
+		return rolloutRecheckInterval, nil
+	}
+
+	now := time.Now()
+	if rollout.WaveHealthyTime == nil {
+		rollout.WaveHealthyTime = &metav1.Time{Time: now}
+	}
+	if strategy := configMapSyncer.GetSpec().RolloutStrategy; strategy != nil && strategy.Paused {
+		return 0, nil
+	}
+	if wave != nil {
+		if wait := wave.pause - now.Sub(rollout.WaveHealthyTime.Time); wait > 0 {
+			// Canary workloads are watched for the whole soak time
+			if wave.canaries != nil {
+				return min(wait, rolloutRecheckInterval), nil
+			}
+			return wait, nil
+		}
+	}
+
+	rollout.CurrentWave++
+	rollout.WaveHealthyTime = nil
+	if rollout.CurrentWave < rollout.TotalWaves {
+		name := waveName(waves, int(rollout.CurrentWave))
+		log.FromContext(ctx).Info("Starting rollout wave", "wave", name)
+		r.event(configMapSyncer, corev1.EventTypeNormal, EventReasonRolloutWaveStarted,
+			"Rolling out revision %s to %s", shortRevision(rollout.Revision), name)
+	}
+	return time.Second, nil
+}
+
+// waveHealthy reports whether every target of the current and earlier waves
+// is in sync and the workloads of the current wave are ready. For canaries
+// these are the workloads referencing a target, which have to stay ready for
+// the soak time; a reason to halt the rollout is returned when one did not.
+// For other waves, with the WorkloadsReady health check, these are the
+// workloads restarted for the revision.
+func (r *ConfigMapSyncerReconciler) waveHealthy(
+	ctx context.Context,
+	configMapSyncer syncv1alpha1.Syncer,
+	wave *rolloutWave,
+	result *syncResult,
+	clusters map[string]*targetCluster,
+) (bool, string, error) {
+	if result.failed() > 0 || len(result.incompleteClusters) > 0 {
+		return false, "", nil
+	}
+	canary := wave != nil && wave.canaries != nil
+	if !canary {
+		strategy := configMapSyncer.GetSpec().RolloutStrategy
+		if strategy == nil || strategy.HealthCheck == RolloutHealthCheckNone || configMapSyncer.GetSpec().RestartWorkloads == nil {
+			return true, "", nil
+		}
+	}
+
+	rollout := configMapSyncer.GetStatus().Rollout
+	kind := targetKind(configMapSyncer)
+	for key := range result.waveNamespaces {
+		clusterName, namespace := splitNamespaceKey(key)
+		cluster, ok := clusters[clusterName]
+		if !ok {
+			return false, "", nil
+		}
+		workloads, err := listWorkloads(ctx, cluster.reader, namespace, labels.Everything())
+		if err != nil {
+			return false, "", newTransientError("list workloads", err)
+		}
+
+		var targets []string
+		for _, status := range result.statuses {
+			if status.Cluster == clusterName && status.Namespace == namespace && status.ConfigMapName != "" {
+				targets = append(targets, status.ConfigMapName)
+			}
+		}
+
+		for _, w := range workloads {
+			if canary && !slices.ContainsFunc(targets, func(target string) bool {
+				return references(&w.template.Spec, kind, target)
+			}) || !canary && !restartedFor(w, rollout.Revision) {
+				continue
+			}
+
+			name := fmt.Sprintf("%s %s/%s", w.kind, namespace, w.object.GetName())
+			if canary && w.failed() {
+				return false, fmt.Sprintf("%s exceeded its progress deadline", name), nil
+			}
+			if w.ready() {
+				continue
+			}
+			if canary && rollout.WaveHealthyTime != nil {
+				return false, fmt.Sprintf("%s became unready during the soak time", name), nil
+			}
+			log.FromContext(ctx).Info("Waiting for workload to become ready", "workload", name)
+			return false, "", nil
+		}
+	}
+	return true, "", nil
+}
+
+// restartedFor reports whether the workload was restarted for the revision
+func restartedFor(w workload, revision string) bool {
+	for key, value := range w.template.Annotations {
+		if strings.HasPrefix(key, RestartAnnotationPrefix) && value == revision {
+			return true
+		}
+	}
+	return false
+}
+
+// ready reports whether the workload finished rolling out its current pod template
+func (w workload) ready() bool {
+	switch obj := w.object.(type) {
+	case *appsv1.Deployment:
+		replicas := replicasOf(obj.Spec.Replicas)
+		return obj.Status.ObservedGeneration >= obj.Generation && obj.Status.Replicas == replicas &&
+			obj.Status.UpdatedReplicas == replicas && obj.Status.AvailableReplicas == replicas
+	case *appsv1.StatefulSet:
+		replicas := replicasOf(obj.Spec.Replicas)
+		return obj.Status.ObservedGeneration >= obj.Generation && obj.Status.UpdatedReplicas == replicas &&
+			obj.Status.ReadyReplicas == replicas && obj.Status.CurrentRevision == obj.Status.UpdateRevision
+	case *appsv1.DaemonSet:
+		desired := obj.Status.DesiredNumberScheduled
+		return obj.Status.ObservedGeneration >= obj.Generation && obj.Status.UpdatedNumberScheduled == desired &&
+			obj.Status.NumberAvailable == desired
+	}
+	return true
+}
+
+// failed reports whether the workload gave up rolling out its pod template.
+// Only Deployments report this, through their progress deadline.
+func (w workload) failed() bool {
+	deployment, ok := w.object.(*appsv1.Deployment)
+	if !ok {
+		return false
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// replicasOf returns the desired number of replicas, which defaults to one
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// setRolloutConditions reports a rollout in progress in the Ready and
+// Progressing conditions and a halted rollout in the RolloutHalted condition
+func (r *ConfigMapSyncerReconciler) setRolloutConditions(configMapSyncer syncv1alpha1.Syncer, result *syncResult) {
+	if rolloutWaves(configMapSyncer) == nil {
+		meta.RemoveStatusCondition(&configMapSyncer.GetStatus().Conditions, ConditionTypeRolloutHalted)
+		return
+	}
+	rollout := rolloutInProgress(configMapSyncer)
+	if rollout == nil || !rollout.Halted {
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:    ConditionTypeRolloutHalted,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonRolloutNotHalted,
+			Message: "The rollout is not halted",
+		})
+	}
+	if rollout == nil {
+		return
+	}
+	waves := rolloutWaves(configMapSyncer)
+	total := result.total()
+	pending := result.pending()
+
+	if rollout.Halted {
+		message := fmt.Sprintf("Rollout of revision %s halted in %s: %s", shortRevision(rollout.Revision),
+			waveName(waves, int(rollout.CurrentWave)), rollout.Message)
+		for _, condition := range []metav1.Condition{
+			{Type: ConditionTypeRolloutHalted, Status: metav1.ConditionTrue},
+			{Type: ConditionTypeProgressing, Status: metav1.ConditionFalse},
+			{Type: ConditionTypeReady, Status: metav1.ConditionFalse},
+		} {
+			condition.Reason = ConditionReasonCanaryUnhealthy
+			condition.Message = message
+			r.setCondition(configMapSyncer, condition)
+		}
+		return
+	}
+
+	reason := ConditionReasonRolloutInProgress
+	message := fmt.Sprintf("Rolling out revision %s: wave %d/%d (%s)", shortRevision(rollout.Revision),
+		rollout.CurrentWave+1, rollout.TotalWaves, waveName(waves, int(rollout.CurrentWave)))
+	if strategy := configMapSyncer.GetSpec().RolloutStrategy; strategy != nil && strategy.Paused {
+		reason = ConditionReasonRolloutPaused
+		message += ", paused"
+	}
+	r.setCondition(configMapSyncer, metav1.Condition{
+		Type:    ConditionTypeProgressing,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+
+	if pending > 0 && result.failed() == 0 {
+		r.setCondition(configMapSyncer, metav1.Condition{
+			Type:   ConditionTypeReady,
+			Status: metav1.ConditionFalse,
+			Reason: reason,
+			Message: fmt.Sprintf("%d/%d targets synced, %d waiting for later waves", total-pending, total, pending) +
+				skippedSuffix(result),
+		})
+	}
+}
+
+// shortRevision shortens a content hash for messages
+func shortRevision(revision string) string {
+	if len(revision) > 12 {
+		return revision[:12]
+	}
+	return revision
+}
+
+// splitNamespaceKey splits a key built by namespaceKey into cluster and namespace
+func splitNamespaceKey(key string) (string, string) {
+	if cluster, namespace, ok := strings.Cut(key, "/"); ok {
+		return cluster, namespace
+	}
+	return "", key
+}
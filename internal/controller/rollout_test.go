@@ -0,0 +1,215 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Progressive rollouts", func() {
+	const (
+		resourceName = "rollout-syncer"
+		masterName   = "rollout-config"
+		firstWave    = "rollout-first"
+		secondWave   = "rollout-second"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+
+	BeforeEach(func() {
+		for name, labels := range map[string]map[string]string{
+			firstWave:  {"rollout-wave": "first"},
+			secondWave: nil,
+		} {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		}
+		createMaster(ctx, masterName, map[string]string{"color": "blue"})
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+		for _, namespace := range []string{"default", firstWave, secondWave} {
+			target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: namespace}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, target))).To(Succeed())
+		}
+	})
+
+	create := func(paused bool) *syncv1alpha1.ClusterConfigMapSyncer {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{firstWave, secondWave},
+				RolloutStrategy: &syncv1alpha1.RolloutStrategy{
+					Waves: []syncv1alpha1.RolloutWave{{
+						Name:              "first",
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"rollout-wave": "first"}},
+					}},
+					HealthCheck: RolloutHealthCheckNone,
+					Paused:      paused,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		return resource
+	}
+
+	targetExists := func(namespace string) bool {
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: namespace}, &corev1.ConfigMap{})
+		Expect(client.IgnoreNotFound(err)).To(Succeed())
+		return !errors.IsNotFound(err)
+	}
+
+	It("should roll the master out wave by wave", func() {
+		resource := create(false)
+		controllerReconciler := newClusterReconciler()
+		reconcileOnce(ctx, controllerReconciler, resource)
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(targetExists(firstWave)).To(BeTrue())
+		Expect(targetExists(secondWave)).To(BeFalse())
+		Expect(resource.Status.Rollout).NotTo(BeNil())
+		Expect(resource.Status.Rollout.CurrentWave).To(Equal(int32(1)))
+		Expect(resource.Status.Rollout.TotalWaves).To(Equal(int32(2)))
+		Expect(resource.Status.SyncStatuses).To(ContainElement(And(
+			HaveField("Namespace", secondWave),
+			HaveField("Status", SyncStatusPending),
+		)))
+		progressing := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeProgressing)
+		Expect(progressing).NotTo(BeNil())
+		Expect(progressing.Reason).To(Equal(ConditionReasonRolloutInProgress))
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(targetExists(secondWave)).To(BeTrue())
+		Expect(resource.Status.Rollout.CurrentWave).To(Equal(int32(2)))
+		Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionTypeReady)).To(BeTrue())
+	})
+
+	It("should not start the next wave while paused", func() {
+		resource := create(true)
+		controllerReconciler := newClusterReconciler()
+		for range 3 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+		Expect(targetExists(firstWave)).To(BeTrue())
+		Expect(targetExists(secondWave)).To(BeFalse())
+		Expect(resource.Status.Rollout.CurrentWave).To(Equal(int32(0)))
+		Expect(resource.Status.Rollout.WaveHealthyTime).NotTo(BeNil())
+		progressing := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeProgressing)
+		Expect(progressing).NotTo(BeNil())
+		Expect(progressing.Reason).To(Equal(ConditionReasonRolloutPaused))
+	})
+})
+
+var _ = Describe("Canary namespaces", func() {
+	const (
+		resourceName = "canary-syncer"
+		masterName   = "canary-config"
+		canary       = "canary-first"
+		remaining    = "canary-remaining"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+
+	BeforeEach(func() {
+		for _, name := range []string{canary, remaining} {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		}
+		createMaster(ctx, masterName, map[string]string{"color": "blue"})
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+		for _, namespace := range []string{"default", canary, remaining} {
+			target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: namespace}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, target))).To(Succeed())
+		}
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "canary-app", Namespace: canary}}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, deployment))).To(Succeed())
+	})
+
+	It("should halt the rollout when a canary workload fails", func() {
+		labels := map[string]string{"app": "canary-app"}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "canary-app", Namespace: canary},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:    "app",
+							Image:   "busybox",
+							EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: masterName}}}},
+						}},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+		deployment.Status.Conditions = []appsv1.DeploymentCondition{{
+			Type:   appsv1.DeploymentProgressing,
+			Status: corev1.ConditionFalse,
+			Reason: "ProgressDeadlineExceeded",
+		}}
+		Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{canary, remaining},
+				Canary:           &syncv1alpha1.Canary{Namespaces: []string{canary}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+
+		for range 3 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+
+		Expect(resource.Status.Rollout).NotTo(BeNil())
+		Expect(resource.Status.Rollout.Halted).To(BeTrue())
+		Expect(resource.Status.Rollout.CurrentWave).To(Equal(int32(0)))
+		halted := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeRolloutHalted)
+		Expect(halted).NotTo(BeNil())
+		Expect(halted.Status).To(Equal(metav1.ConditionTrue))
+		Expect(halted.Message).To(ContainSubstring(shortRevision(resource.Status.Rollout.Revision)))
+
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: canary}, &corev1.ConfigMap{})
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: remaining}, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
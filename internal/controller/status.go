@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+// patchStatus writes the status of the syncer with a merge patch against
+// original, the object as it was read at the start of the reconcile. Nothing is
+// written when only timestamps differ, so an idle syncer does not bump its
+// resourceVersion on every sync interval. On a conflict the latest object is
+// fetched and the desired status is diffed against it again.
+func (r *ConfigMapSyncerReconciler) patchStatus(
+	ctx context.Context,
+	original syncv1alpha1.Syncer,
+	configMapSyncer syncv1alpha1.Syncer,
+) error {
+	base := original
+	desired := configMapSyncer.GetStatus().DeepCopy()
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if statusEqualIgnoringTimestamps(base.GetStatus(), desired) {
+			return nil
+		}
+
+		patched := base.DeepCopyObject().(syncv1alpha1.Syncer)
+		*patched.GetStatus() = *desired.DeepCopy()
+		err := r.Status().Patch(ctx, patched, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+		if errors.IsConflict(err) {
+			latest := base.DeepCopyObject().(syncv1alpha1.Syncer)
+			if getErr := r.Get(ctx, client.ObjectKeyFromObject(base), latest); getErr != nil {
+				return getErr
+			}
+			base = latest
+			return err
+		}
+		if err == nil {
+			configMapSyncer.SetResourceVersion(patched.GetResourceVersion())
+		}
+		return err
+	})
+	if err != nil {
+		return newTransientError(fmt.Sprintf("update %s status", kindOf(configMapSyncer)), err)
+	}
+	return nil
+}
+
+// statusEqualIgnoringTimestamps compares two statuses without the sync and
+// condition transition timestamps, which change on every reconcile
+func statusEqualIgnoringTimestamps(a, b *syncv1alpha1.ConfigMapSyncerStatus) bool {
+	return equality.Semantic.DeepEqual(withoutTimestamps(a), withoutTimestamps(b))
+}
+
+func withoutTimestamps(status *syncv1alpha1.ConfigMapSyncerStatus) *syncv1alpha1.ConfigMapSyncerStatus {
+	stripped := status.DeepCopy()
+	stripped.LastSyncTime = nil
+	for i := range stripped.Conditions {
+		stripped.Conditions[i].LastTransitionTime = metav1.Time{}
+	}
+	stripped.SyncStatuses = syncStatusesWithoutTimestamps(stripped.SyncStatuses)
+	stripped.RecentFailures = syncStatusesWithoutTimestamps(stripped.RecentFailures)
+	return stripped
+}
+
+// syncStatusesEqualIgnoringTimestamps compares two lists of per-target statuses
+// without their sync timestamps
+func syncStatusesEqualIgnoringTimestamps(a, b []syncv1alpha1.SyncStatus) bool {
+	return equality.Semantic.DeepEqual(syncStatusesWithoutTimestamps(a), syncStatusesWithoutTimestamps(b))
+}
+
+func syncStatusesWithoutTimestamps(statuses []syncv1alpha1.SyncStatus) []syncv1alpha1.SyncStatus {
+	if statuses == nil {
+		return nil
+	}
+	stripped := make([]syncv1alpha1.SyncStatus, len(statuses))
+	for i := range statuses {
+		stripped[i] = statuses[i]
+		stripped[i].LastSyncTime = nil
+	}
+	return stripped
+}
@@ -25,12 +25,16 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
 	// +kubebuilder:scaffold:imports
@@ -114,3 +118,46 @@ func getFirstFoundEnvTestBinaryDir() string {
 	}
 	return ""
 }
+
+// createMaster creates a master ConfigMap in the default namespace, a master
+// left over by an earlier test is reused
+func createMaster(ctx context.Context, name string, data map[string]string) *corev1.ConfigMap {
+	master := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       data,
+	}
+	ExpectWithOffset(1, client.IgnoreAlreadyExists(k8sClient.Create(ctx, master))).To(Succeed())
+	return master
+}
+
+// deleteSyncer deletes a syncer without waiting for the controller, which does
+// not run in the tests, to remove its finalizer
+func deleteSyncer(ctx context.Context, resource syncv1alpha1.Syncer, key types.NamespacedName) {
+	ExpectWithOffset(1, k8sClient.Get(ctx, key, resource)).To(Succeed())
+	resource.SetFinalizers(nil)
+	ExpectWithOffset(1, k8sClient.Update(ctx, resource)).To(Succeed())
+	ExpectWithOffset(1, k8sClient.Delete(ctx, resource)).To(Succeed())
+}
+
+// newClusterReconciler returns a reconciler of ClusterConfigMapSyncers using
+// the client of the test environment
+func newClusterReconciler() *ClusterConfigMapSyncerReconciler {
+	return &ClusterConfigMapSyncerReconciler{ConfigMapSyncerReconciler{
+		Client: k8sClient,
+		Scheme: k8sClient.Scheme(),
+	}}
+}
+
+// reconcileOnce reconciles the ClusterConfigMapSyncer once and reads it back
+// into resource, it returns the result of the reconciliation
+func reconcileOnce(
+	ctx context.Context,
+	controllerReconciler *ClusterConfigMapSyncerReconciler,
+	resource *syncv1alpha1.ClusterConfigMapSyncer,
+) reconcile.Result {
+	key := client.ObjectKeyFromObject(resource)
+	result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, k8sClient.Get(ctx, key, resource)).To(Succeed())
+	return result
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/schedule"
+)
+
+const (
+	// ConditionReasonWaitingForWindow is the reason while changes are deferred until the next sync window
+	ConditionReasonWaitingForWindow = "WaitingForWindow"
+)
+
+// now returns the time sync windows are evaluated at
+func (r *ConfigMapSyncerReconciler) now() time.Time {
+	if r.Clock == nil {
+		return clock.RealClock{}.Now()
+	}
+	return r.Clock.Now()
+}
+
+// syncWindows parses the sync windows of the syncer
+func syncWindows(configMapSyncer syncv1alpha1.Syncer) ([]schedule.Window, error) {
+	var windows []schedule.Window
+	for i, window := range configMapSyncer.GetSpec().SyncWindows {
+		cron, err := schedule.ParseCron(window.Schedule)
+		if err != nil {
+			return nil, newValidationError(fmt.Sprintf("spec.syncWindows[%d].schedule", i), err)
+		}
+		location := time.UTC
+		if window.TimeZone != "" {
+			location, err = time.LoadLocation(window.TimeZone)
+			if err != nil {
+				return nil, newValidationError(fmt.Sprintf("spec.syncWindows[%d].timeZone", i), err)
+			}
+		}
+		windows = append(windows, schedule.Window{
+			Kind:     window.Kind,
+			Schedule: cron,
+			Duration: window.Duration.Duration,
+			Location: location,
+		})
+	}
+	return windows, nil
+}
+
+// waitForWindow records in the status when the sync windows open next and
+// returns how long to wait for it, zero if they are open. Closed windows that
+// never open are waited for on the sync interval.
+func (r *ConfigMapSyncerReconciler) waitForWindow(configMapSyncer syncv1alpha1.Syncer) (time.Duration, error) {
+	windows, err := syncWindows(configMapSyncer)
+	if err != nil {
+		return 0, err
+	}
+	now := r.now()
+	next := schedule.NextOpen(windows, now)
+	if next.Equal(now) {
+		configMapSyncer.GetStatus().NextSyncWindow = nil
+		return 0, nil
+	}
+
+	message := "Sync windows are closed and do not open again"
+	wait := syncInterval(configMapSyncer)
+	configMapSyncer.GetStatus().NextSyncWindow = nil
+	if !next.IsZero() {
+		message = fmt.Sprintf("Sync windows are closed, changes are deferred until %s", next.UTC().Format(time.RFC3339))
+		wait = next.Sub(now)
+		configMapSyncer.GetStatus().NextSyncWindow = &metav1.Time{Time: next}
+	}
+	r.setCondition(configMapSyncer, metav1.Condition{
+		Type:    ConditionTypeProgressing,
+		Status:  metav1.ConditionFalse,
+		Reason:  ConditionReasonWaitingForWindow,
+		Message: message,
+	})
+	return wait, nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Sync windows", func() {
+	const (
+		resourceName    = "window-syncer"
+		masterName      = "window-config"
+		targetNamespace = "window-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+	targetKey := types.NamespacedName{Name: masterName, Namespace: targetNamespace}
+
+	BeforeEach(func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+		createMaster(ctx, masterName, map[string]string{"color": "blue"})
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+		for _, namespace := range []string{"default", targetNamespace} {
+			target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: namespace}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, target))).To(Succeed())
+		}
+	})
+
+	It("should defer writes until the next allow window", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				SyncWindows: []syncv1alpha1.SyncWindow{{
+					Kind:     "Allow",
+					Schedule: "0 9 * * *",
+					Duration: metav1.Duration{Duration: time.Hour},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		clock := clocktesting.NewFakePassiveClock(time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC))
+		controllerReconciler := &ClusterConfigMapSyncerReconciler{ConfigMapSyncerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Clock:  clock,
+		}}
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		result := reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+
+		Expect(resource.Status.NextSyncWindow).NotTo(BeNil())
+		Expect(resource.Status.NextSyncWindow.Time).To(BeTemporally("==", time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)))
+		progressing := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeProgressing)
+		Expect(progressing).NotTo(BeNil())
+		Expect(progressing.Reason).To(Equal(ConditionReasonWaitingForWindow))
+		err := k8sClient.Get(ctx, targetKey, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		clock.SetTime(time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC))
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(k8sClient.Get(ctx, targetKey, &corev1.ConfigMap{})).To(Succeed())
+		Expect(resource.Status.NextSyncWindow).To(BeNil())
+	})
+
+	It("should defer writes during deny windows of their time zone", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				SyncWindows: []syncv1alpha1.SyncWindow{{
+					Kind:     "Deny",
+					Schedule: "0 9 * * *",
+					Duration: metav1.Duration{Duration: time.Hour},
+					TimeZone: "Europe/Berlin",
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		// 09:30 in Berlin
+		clock := clocktesting.NewFakePassiveClock(time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC))
+		controllerReconciler := &ClusterConfigMapSyncerReconciler{ConfigMapSyncerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Clock:  clock,
+		}}
+
+		reconcileOnce(ctx, controllerReconciler, resource)
+		result := reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(result.RequeueAfter).To(Equal(30 * time.Minute))
+		Expect(resource.Status.NextSyncWindow).NotTo(BeNil())
+		Expect(resource.Status.NextSyncWindow.Time).To(BeTemporally("==", time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)))
+		err := k8sClient.Get(ctx, targetKey, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		// 09:30 in UTC is after the deny window in Berlin
+		clock.SetTime(time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC))
+		reconcileOnce(ctx, controllerReconciler, resource)
+		Expect(k8sClient.Get(ctx, targetKey, &corev1.ConfigMap{})).To(Succeed())
+		Expect(resource.Status.NextSyncWindow).To(BeNil())
+	})
+
+	It("should reject unknown time zones", func() {
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				SyncWindows: []syncv1alpha1.SyncWindow{{
+					Kind:     "Allow",
+					Schedule: "0 9 * * *",
+					Duration: metav1.Duration{Duration: time.Hour},
+					TimeZone: "Mars/Olympus_Mons",
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		controllerReconciler := newClusterReconciler()
+
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(IsValidationError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.syncWindows[0].timeZone")))
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		ready := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeReady)
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Reason).To(Equal(ConditionReasonInvalidSpec))
+		err = k8sClient.Get(ctx, targetKey, &corev1.ConfigMap{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
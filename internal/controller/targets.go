@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// KindConfigMap is the kind of ConfigMap masters and targets
+	KindConfigMap = "ConfigMap"
+
+	// KindSecret is the kind of Secret masters and targets
+	KindSecret = "Secret"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Masters and targets of either kind are handled in the shape of a ConfigMap, the helpers
+// below convert them when they are read and written.
+
+// targetKind returns the kind of the targets of the syncer
+func targetKind(configMapSyncer syncv1alpha1.Syncer) string {
+	if configMapSyncer.GetSpec().TargetKind == KindSecret {
+		return KindSecret
+	}
+	return KindConfigMap
+}
+
+// targetSecretType returns the type of the Secret targets of the syncer
+func targetSecretType(configMapSyncer syncv1alpha1.Syncer) corev1.SecretType {
+	if secretType := configMapSyncer.GetSpec().TargetSecretType; secretType != "" {
+		return corev1.SecretType(secretType)
+	}
+	return corev1.SecretTypeOpaque
+}
+
+// statusKind returns the kind recorded in the sync status of a target, which
+// is left empty for ConfigMaps
+func statusKind(kind string) string {
+	if kind == KindConfigMap {
+		return ""
+	}
+	return kind
+}
+
+// kindOfStatus returns the kind of the target of a sync status
+func kindOfStatus(status syncv1alpha1.SyncStatus) string {
+	if status.Kind == "" {
+		return KindConfigMap
+	}
+	return status.Kind
+}
+
+// newTargetObject returns an empty object of the given kind
+func newTargetObject(kind string) client.Object {
+	if kind == KindSecret {
+		return &corev1.Secret{}
+	}
+	return &corev1.ConfigMap{}
+}
+
+// configMapFromSecret returns a ConfigMap with the metadata and data of the
+// Secret. Values that are valid UTF-8 become data, all others binary data.
+func configMapFromSecret(secret *corev1.Secret) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{ObjectMeta: *secret.ObjectMeta.DeepCopy(), Immutable: secret.Immutable}
+	for key, value := range secret.Data {
+		if utf8.Valid(value) {
+			if configMap.Data == nil {
+				configMap.Data = make(map[string]string)
+			}
+			configMap.Data[key] = string(value)
+			continue
+		}
+		if configMap.BinaryData == nil {
+			configMap.BinaryData = make(map[string][]byte)
+		}
+		configMap.BinaryData[key] = value
+	}
+	return configMap
+}
+
+// secretFromConfigMap returns a Secret of the given type with the metadata and
+// the data and binary data of the ConfigMap
+func secretFromConfigMap(configMap *corev1.ConfigMap, secretType corev1.SecretType) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: *configMap.ObjectMeta.DeepCopy(),
+		Type:       secretType,
+		Immutable:  configMap.Immutable,
+		Data:       make(map[string][]byte, len(configMap.Data)+len(configMap.BinaryData)),
+	}
+	for key, value := range configMap.Data {
+		secret.Data[key] = []byte(value)
+	}
+	for key, value := range configMap.BinaryData {
+		secret.Data[key] = value
+	}
+	return secret
+}
+
+// readAsConfigMap reads the object of the given kind in the shape of a ConfigMap
+func readAsConfigMap(ctx context.Context, reader client.Reader, key types.NamespacedName, kind string) (*corev1.ConfigMap, error) {
+	if kind != KindSecret {
+		configMap := &corev1.ConfigMap{}
+		if err := reader.Get(ctx, key, configMap); err != nil {
+			return nil, err
+		}
+		return configMap, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, key, secret); err != nil {
+		return nil, err
+	}
+	return configMapFromSecret(secret), nil
+}
+
+// listTargets lists the targets of the given kind in the namespace that match
+// the selector, in the shape of ConfigMaps
+func listTargets(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	selector labels.Selector,
+	kind string,
+) ([]corev1.ConfigMap, error) {
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}}
+	if kind != KindSecret {
+		configMapList := &corev1.ConfigMapList{}
+		if err := reader.List(ctx, configMapList, opts...); err != nil {
+			return nil, err
+		}
+		return configMapList.Items, nil
+	}
+
+	secretList := &corev1.SecretList{}
+	if err := reader.List(ctx, secretList, opts...); err != nil {
+		return nil, err
+	}
+	targets := make([]corev1.ConfigMap, 0, len(secretList.Items))
+	for i := range secretList.Items {
+		targets = append(targets, *configMapFromSecret(&secretList.Items[i]))
+	}
+	return targets, nil
+}
+
+// writeTarget creates or updates the target of the given kind from its shape
+// as a ConfigMap
+func writeTarget(
+	ctx context.Context,
+	c client.Client,
+	target *corev1.ConfigMap,
+	kind string,
+	secretType corev1.SecretType,
+	create bool,
+) error {
+	var obj client.Object = target
+	if kind == KindSecret {
+		obj = secretFromConfigMap(target, secretType)
+	}
+	if create {
+		return c.Create(ctx, obj)
+	}
+	return c.Update(ctx, obj)
+}
+
+// secretTypeChanged reports whether the update of a Secret target was rejected
+// because it changes the type of the Secret, which is immutable
+func secretTypeChanged(err error) bool {
+	var statusErr *apierrors.StatusError
+	if !apierrors.IsInvalid(err) || !goerrors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return false
+	}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Field == "type" {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceableSecret rejects the replacement of Secret targets the controller
+// did not create, those keep their type and the sync fails
+func replaceableSecret(target *corev1.ConfigMap, secretType corev1.SecretType) error {
+	if target.Annotations[CreatedByControllerAnnotation] != "true" {
+		return fmt.Errorf("the Secret was not created by the controller and its type cannot be changed to %s", secretType)
+	}
+	return nil
+}
+
+// replaceSecret deletes a Secret target and creates it again with the new type
+func replaceSecret(ctx context.Context, c client.Client, target *corev1.ConfigMap, secretType corev1.SecretType) error {
+	if err := replaceableSecret(target, secretType); err != nil {
+		return err
+	}
+	secret := secretFromConfigMap(target, secretType)
+	if err := c.Delete(ctx, secret, client.Preconditions{UID: &secret.UID, ResourceVersion: &secret.ResourceVersion}); err != nil {
+		return fmt.Errorf("failed to delete the Secret to change its type: %w", err)
+	}
+	secret.UID = ""
+	secret.ResourceVersion = ""
+	return c.Create(ctx, secret)
+}
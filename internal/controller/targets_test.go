@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/config"
+)
+
+var _ = Describe("Secret targets and masters", func() {
+	const (
+		resourceName    = "secret-syncer"
+		masterName      = "secret-master"
+		targetNamespace = "secret-target"
+	)
+
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: resourceName}
+
+	BeforeEach(func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, namespace))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteSyncer(ctx, &syncv1alpha1.ClusterConfigMapSyncer{}, typeNamespacedName)
+	})
+
+	reconcileTwice := func(cfg config.ControllerConfig) {
+		controllerReconciler := &ClusterConfigMapSyncerReconciler{ConfigMapSyncerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: cfg,
+		}}
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{ObjectMeta: metav1.ObjectMeta{Name: resourceName}}
+		for range 2 {
+			reconcileOnce(ctx, controllerReconciler, resource)
+		}
+	}
+
+	It("should propagate a ConfigMap into Secrets of the chosen type", func() {
+		master := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: "default"},
+			Data:       map[string]string{"token": "abc"},
+			BinaryData: map[string][]byte{"blob": {0xff, 0x00}},
+		}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, master))).To(Succeed())
+		DeferCleanup(func() { Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, master))).To(Succeed()) })
+
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap:  syncv1alpha1.ConfigMapReference{Name: masterName, Namespace: "default"},
+				TargetNamespaces: []string{targetNamespace},
+				TargetKind:       KindSecret,
+				TargetSecretType: "example.com/config",
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		reconcileTwice(config.ControllerConfig{})
+
+		target := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Type).To(Equal(corev1.SecretType("example.com/config")))
+		Expect(target.Data).To(Equal(map[string][]byte{"token": []byte("abc"), "blob": {0xff, 0x00}}))
+		Expect(target.Labels).To(HaveKey(SourceConfigMapLabel))
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(resource.Status.SyncStatuses).To(ContainElement(And(
+			HaveField("Kind", KindSecret),
+			HaveField("Status", SyncStatusSynced),
+		)))
+
+		By("recreating the targets when their type changes")
+		resource.Spec.TargetSecretType = string(corev1.SecretTypeOpaque)
+		Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		reconcileTwice(config.ControllerConfig{})
+
+		recreated := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, recreated)).To(Succeed())
+		Expect(recreated.Type).To(Equal(corev1.SecretTypeOpaque))
+		Expect(recreated.UID).NotTo(Equal(target.UID))
+		Expect(recreated.Data).To(Equal(target.Data))
+	})
+
+	It("should only read a Secret master when the controller allows it", func() {
+		master := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: masterName, Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("s3cret")},
+		}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, master))).To(Succeed())
+		DeferCleanup(func() { Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, master))).To(Succeed()) })
+
+		resource := &syncv1alpha1.ClusterConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap: syncv1alpha1.ConfigMapReference{
+					Name:      masterName,
+					Namespace: "default",
+					Kind:      KindSecret,
+				},
+				TargetNamespaces: []string{targetNamespace},
+			},
+		}
+		Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+		controllerReconciler := newClusterReconciler()
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(IsValidationError(err)).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		ready := meta.FindStatusCondition(resource.Status.Conditions, ConditionTypeReady)
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Reason).To(Equal(ConditionReasonInvalidSpec))
+
+		cfg := config.Default()
+		cfg.AllowSecretMasters = true
+		reconcileTwice(cfg)
+
+		target := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: masterName, Namespace: targetNamespace}, target)).To(Succeed())
+		Expect(target.Data).To(Equal(map[string]string{"password": "s3cret"}))
+	})
+})
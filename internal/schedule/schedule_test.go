@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron", func() {
+	at := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	DescribeTable("should find the next activation",
+		func(expression, after, next string) {
+			cron, err := ParseCron(expression)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cron.Next(at(after))).To(Equal(at(next)))
+		},
+		Entry("every minute", "* * * * *", "2026-03-02T10:00:30Z", "2026-03-02T10:01:00Z"),
+		Entry("strictly after", "0 10 * * *", "2026-03-02T10:00:00Z", "2026-03-03T10:00:00Z"),
+		Entry("steps", "*/15 * * * *", "2026-03-02T10:16:00Z", "2026-03-02T10:30:00Z"),
+		Entry("weekday names", "0 9 * * mon-fri", "2026-03-06T10:00:00Z", "2026-03-09T09:00:00Z"),
+		Entry("Sunday as 7", "0 0 * * 7", "2026-03-02T00:00:00Z", "2026-03-08T00:00:00Z"),
+		Entry("month and day", "30 2 1 jan *", "2026-03-02T00:00:00Z", "2027-01-01T02:30:00Z"),
+		Entry("either day field", "0 0 13 * fri", "2026-03-02T00:00:00Z", "2026-03-06T00:00:00Z"),
+	)
+
+	It("should reject invalid expressions", func() {
+		for _, expression := range []string{"* * * *", "60 * * * *", "* * * * foo", "5-1 * * * *", "*/0 * * * *"} {
+			_, err := ParseCron(expression)
+			Expect(err).To(HaveOccurred(), expression)
+		}
+	})
+
+	It("should give up on expressions that never match", func() {
+		cron, err := ParseCron("0 0 30 2 *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cron.Next(at("2026-01-01T00:00:00Z")).IsZero()).To(BeTrue())
+	})
+})
+
+var _ = Describe("Windows", func() {
+	window := func(kind, expression string, duration time.Duration) Window {
+		cron, err := ParseCron(expression)
+		Expect(err).NotTo(HaveOccurred())
+		return Window{Kind: kind, Schedule: cron, Duration: duration}
+	}
+	at := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	It("should be open without windows", func() {
+		Expect(Open(nil, at("2026-03-02T10:00:00Z"))).To(BeTrue())
+	})
+
+	It("should only be open during allow windows", func() {
+		windows := []Window{window(KindAllow, "0 9 * * mon-fri", 8*time.Hour)}
+		Expect(Open(windows, at("2026-03-02T09:00:00Z"))).To(BeTrue())
+		Expect(Open(windows, at("2026-03-02T16:59:00Z"))).To(BeTrue())
+		Expect(Open(windows, at("2026-03-02T17:00:00Z"))).To(BeFalse())
+		Expect(NextOpen(windows, at("2026-03-06T18:00:00Z"))).To(Equal(at("2026-03-09T09:00:00Z")))
+	})
+
+	It("should let deny windows take precedence", func() {
+		windows := []Window{
+			window(KindAllow, "0 0 * * *", 24*time.Hour),
+			window(KindDeny, "0 12 * * *", time.Hour),
+		}
+		Expect(Open(windows, at("2026-03-02T11:59:00Z"))).To(BeTrue())
+		Expect(Open(windows, at("2026-03-02T12:30:00Z"))).To(BeFalse())
+		Expect(NextOpen(windows, at("2026-03-02T12:30:00Z"))).To(Equal(at("2026-03-02T13:00:00Z")))
+	})
+
+	It("should evaluate schedules in the location of the window", func() {
+		location, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+		w := window(KindAllow, "0 9 * * *", time.Hour)
+		w.Location = location
+		Expect(Open([]Window{w}, at("2026-03-02T08:30:00Z"))).To(BeTrue())
+		Expect(Open([]Window{w}, at("2026-03-02T09:30:00Z"))).To(BeFalse())
+	})
+
+	It("should close deny windows that started the day before", func() {
+		windows := []Window{window(KindDeny, "0 23 * * *", 2*time.Hour)}
+		Expect(Open(windows, at("2026-03-03T00:30:00Z"))).To(BeFalse())
+		Expect(NextOpen(windows, at("2026-03-03T00:30:00Z"))).To(Equal(at("2026-03-03T01:00:00Z")))
+	})
+
+	It("should combine windows of different locations", func() {
+		location, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+		deny := window(KindDeny, "0 9 * * *", time.Hour)
+		deny.Location = location
+		windows := []Window{window(KindAllow, "0 0 * * *", 24*time.Hour), deny}
+		Expect(Open(windows, at("2026-03-02T09:30:00Z"))).To(BeTrue())
+		Expect(Open(windows, at("2026-03-02T14:30:00Z"))).To(BeFalse())
+		Expect(NextOpen(windows, at("2026-03-02T14:30:00Z"))).To(BeTemporally("==", at("2026-03-02T15:00:00Z")))
+	})
+
+	It("should follow daylight saving time changes", func() {
+		location, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+		w := window(KindAllow, "0 9 * * *", time.Hour)
+		w.Location = location
+		Expect(NextOpen([]Window{w}, at("2026-03-27T10:00:00Z"))).To(BeTemporally("==", at("2026-03-28T08:00:00Z")))
+		Expect(NextOpen([]Window{w}, at("2026-03-29T10:00:00Z"))).To(BeTemporally("==", at("2026-03-30T07:00:00Z")))
+	})
+
+	It("should report when windows never open", func() {
+		windows := []Window{window(KindDeny, "* * * * *", time.Hour)}
+		Expect(NextOpen(windows, at("2026-03-02T12:30:00Z")).IsZero()).To(BeTrue())
+	})
+})
@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"time"
+	// Sync windows name IANA time zones, the controller image has no zoneinfo
+	_ "time/tzdata"
+)
+
+const (
+	// KindAllow windows are the only times changes may be propagated
+	KindAllow = "Allow"
+
+	// KindDeny windows are times changes may not be propagated, they take precedence over allow windows
+	KindDeny = "Deny"
+
+	// maxBoundaries bounds the search for the next open window and the
+	// extension of overlapping activations
+	maxBoundaries = 1000
+)
+
+// Window is a time window starting at every activation of a cron schedule
+// and lasting for a duration
+type Window struct {
+	Kind     string
+	Schedule *Cron
+	Duration time.Duration
+
+	// Location is the time zone the schedule is evaluated in, UTC if nil
+	Location *time.Location
+}
+
+// activeAt reports whether t falls into the window and, if so, when the
+// window ends. Overlapping activations extend the window.
+func (w Window) activeAt(t time.Time) (bool, time.Time) {
+	location := w.Location
+	if location == nil {
+		location = time.UTC
+	}
+	t = t.In(location)
+
+	start := w.Schedule.Next(t.Add(-w.Duration))
+	if start.IsZero() || start.After(t) {
+		return false, time.Time{}
+	}
+	// A window that keeps getting extended is only followed for a while, the
+	// end returned is then when it has to be looked at again
+	end := start.Add(w.Duration)
+	next := w.Schedule.Next(start)
+	for range maxBoundaries {
+		if next.IsZero() || next.After(end) {
+			break
+		}
+		end = next.Add(w.Duration)
+		next = w.Schedule.Next(next)
+	}
+	return true, end
+}
+
+// nextStart returns the next activation of the window after t
+func (w Window) nextStart(t time.Time) time.Time {
+	location := w.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return w.Schedule.Next(t.In(location))
+}
+
+// Open reports whether changes may be propagated at t: no deny window is
+// active and, if there are allow windows, one of them is
+func Open(windows []Window, t time.Time) bool {
+	open, _ := openAt(windows, t)
+	return open
+}
+
+// openAt reports whether the windows are open at t and returns the time the
+// state may change next
+func openAt(windows []Window, t time.Time) (bool, time.Time) {
+	allowed, hasAllow, denied := false, false, false
+	var change time.Time
+	earliest := func(candidate time.Time) {
+		if !candidate.IsZero() && candidate.After(t) && (change.IsZero() || candidate.Before(change)) {
+			change = candidate
+		}
+	}
+
+	for _, w := range windows {
+		active, end := w.activeAt(t)
+		if active {
+			earliest(end)
+		} else {
+			earliest(w.nextStart(t))
+		}
+		switch w.Kind {
+		case KindAllow:
+			hasAllow = true
+			allowed = allowed || active
+		case KindDeny:
+			denied = denied || active
+		}
+	}
+	return !denied && (allowed || !hasAllow), change
+}
+
+// NextOpen returns t if the windows are open at t, otherwise the time they
+// open next. It returns the zero time if they do not open in the foreseeable future.
+func NextOpen(windows []Window, t time.Time) time.Time {
+	for range maxBoundaries {
+		open, change := openAt(windows, t)
+		if open {
+			return t
+		}
+		if change.IsZero() {
+			return time.Time{}
+		}
+		t = change
+	}
+	return time.Time{}
+}
@@ -0,0 +1,355 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+// allowedProtocols are the transports git may use. Remote helpers such as
+// ext:: would run arbitrary commands and are never allowed.
+const allowedProtocols = "file:https:http:ssh:git"
+
+// remoteSchemes are the URL schemes of repositories on other hosts
+var remoteSchemes = map[string]bool{"https": true, "http": true, "ssh": true, "git": true}
+
+// Git reads files from Git repositories with the git command line. Every
+// repository is mirrored once into the cache directory and fetched on use.
+// A mirror is removed once no owner uses its repository anymore. It is safe
+// for concurrent use.
+type Git struct {
+	// Dir is the cache directory for the mirrors
+	Dir string
+
+	mu sync.Mutex
+	// repos holds the locks of the repositories that are being used
+	repos map[string]*repository
+	// owners maps every owner to the repository it reads
+	owners map[string]string
+}
+
+// repository serializes the use of a mirror
+type repository struct {
+	mu sync.Mutex
+	// users counts the callers holding or waiting for the lock
+	users int
+}
+
+// NewGit returns a Git source caching its mirrors in dir
+func NewGit(dir string) *Git {
+	return &Git{Dir: dir, repos: map[string]*repository{}, owners: map[string]string{}}
+}
+
+// GitHost returns the host of a remote repository, with its port if the URL
+// has one. Only URLs with the https, http, ssh or git scheme and the scp-like
+// form [user@]host:path are remote, paths, file:// URLs and remote helpers are
+// refused.
+func GitHost(repoURL string) (string, error) {
+	notRemote := fmt.Errorf("%q is not a remote repository, use an https, http, ssh or git URL or user@host:path",
+		repoURL)
+	if strings.Contains(repoURL, "::") {
+		return "", notRemote
+	}
+
+	var host string
+	if strings.Contains(repoURL, "://") {
+		u, err := url.Parse(repoURL)
+		if err != nil || !remoteSchemes[u.Scheme] {
+			return "", notRemote
+		}
+		host = u.Host
+	} else {
+		// git only reads the scp-like form if no slash precedes the first colon
+		colon := strings.Index(repoURL, ":")
+		if colon < 0 || strings.Contains(repoURL[:colon], "/") {
+			return "", notRemote
+		}
+		host = repoURL[:colon]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+	if host == "" || strings.HasPrefix(host, "-") {
+		return "", notRemote
+	}
+	return host, nil
+}
+
+// Fetch reads the files of the source at the configured branch, tag or
+// commit for the owner. The mirror of a repository the owner read before is
+// removed unless another owner still uses it.
+func (g *Git) Fetch(ctx context.Context, owner string, source syncv1alpha1.GitSource) (*Content, error) {
+	if previous := g.use(owner, source.URL); previous != "" {
+		g.remove(previous)
+	}
+
+	unlock := g.lock(source.URL)
+	defer unlock()
+
+	mirror, err := g.mirror(ctx, source.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	rev := "HEAD"
+	switch {
+	case source.Branch != "":
+		rev = "refs/heads/" + source.Branch
+	case source.Tag != "":
+		rev = "refs/tags/" + source.Tag
+	case source.Commit != "":
+		rev = source.Commit
+	}
+	commit, err := g.git(ctx, mirror, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("revision %q not found in %s", rev, source.URL)
+	}
+	commitID := strings.TrimSpace(string(commit))
+
+	return g.read(ctx, mirror, commitID, source.Path)
+}
+
+// Release drops the repository of the owner, whose mirror is removed unless
+// another owner still uses it
+func (g *Git) Release(owner string) {
+	g.mu.Lock()
+	repoURL, ok := g.owners[owner]
+	delete(g.owners, owner)
+	g.mu.Unlock()
+
+	if ok {
+		g.remove(repoURL)
+	}
+}
+
+// use records the repository the owner reads and returns the one it read
+// before if no owner uses that anymore
+func (g *Git) use(owner, repoURL string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	previous, ok := g.owners[owner]
+	g.owners[owner] = repoURL
+	if !ok || previous == repoURL || g.usedLocked(previous) {
+		return ""
+	}
+	return previous
+}
+
+// usedLocked reports whether an owner reads the repository, g.mu must be held
+func (g *Git) usedLocked(repoURL string) bool {
+	for _, used := range g.owners {
+		if used == repoURL {
+			return true
+		}
+	}
+	return false
+}
+
+// remove deletes the mirror of a repository no owner uses. A mirror that
+// cannot be deleted is reused if the repository is read again.
+func (g *Git) remove(repoURL string) {
+	unlock := g.lock(repoURL)
+	defer unlock()
+
+	g.mu.Lock()
+	used := g.usedLocked(repoURL)
+	g.mu.Unlock()
+	if !used {
+		_ = os.RemoveAll(g.mirrorDir(repoURL))
+	}
+}
+
+// lock serializes the use of a single mirror. The lock of a repository is
+// dropped once nobody holds or waits for it.
+func (g *Git) lock(repoURL string) func() {
+	g.mu.Lock()
+	repo, ok := g.repos[repoURL]
+	if !ok {
+		repo = &repository{}
+		g.repos[repoURL] = repo
+	}
+	repo.users++
+	g.mu.Unlock()
+
+	repo.mu.Lock()
+	return func() {
+		repo.mu.Unlock()
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if repo.users--; repo.users == 0 {
+			delete(g.repos, repoURL)
+		}
+	}
+}
+
+// mirrorDir returns the directory of the mirror of a repository
+func (g *Git) mirrorDir(repoURL string) string {
+	sum := sha256.Sum256([]byte(repoURL))
+	return filepath.Join(g.Dir, hex.EncodeToString(sum[:8]))
+}
+
+// mirror clones the repository on first use and fetches it afterwards
+func (g *Git) mirror(ctx context.Context, repoURL string) (string, error) {
+	dir := g.mirrorDir(repoURL)
+
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); err == nil {
+		if _, err := g.git(ctx, dir, "fetch", "--prune", "--tags", "origin"); err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", repoURL, err)
+		}
+		return dir, nil
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(g.Dir, 0o700); err != nil {
+		return "", err
+	}
+	if _, err := g.git(ctx, g.Dir, "clone", "--mirror", "--quiet", "--", repoURL, dir); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", repoURL, err)
+	}
+	return dir, nil
+}
+
+// read returns the files below dir at the given commit
+func (g *Git) read(ctx context.Context, mirror, commit, dir string) (*Content, error) {
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	args := []string{"ls-tree", "-r", "-z", "--full-tree", commit}
+	if dir != "" {
+		args = append(args, "--", dir+"/")
+	}
+	tree, err := g.git(ctx, mirror, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of commit %s: %w", commit, err)
+	}
+
+	var files, objects []string
+	for _, entry := range bytes.Split(tree, []byte{0}) {
+		// Entries look like "<mode> <type> <object>\t<path>"
+		meta, file, ok := strings.Cut(string(entry), "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		files = append(files, file)
+		objects = append(objects, fields[2])
+	}
+	if len(objects) == 0 {
+		return newContent(commit), nil
+	}
+
+	// All blobs are read by a single git process
+	out, err := g.run(ctx, mirror, strings.NewReader(strings.Join(objects, "\n")+"\n"), "cat-file", "--batch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files of commit %s: %w", commit, err)
+	}
+	batch := bufio.NewReader(bytes.NewReader(out))
+
+	content := newContent(commit)
+	for _, file := range files {
+		data, err := readBatchObject(batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		relative := strings.TrimPrefix(file, dir+"/")
+		if dir == "" {
+			relative = file
+		}
+		if err := content.add(relative, data); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// readBatchObject reads the next object from the output of git cat-file
+// --batch, which is a "<object> <type> <size>" line followed by the content
+// and a newline
+func readBatchObject(batch *bufio.Reader) ([]byte, error) {
+	header, err := batch.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected object header %q", strings.TrimSpace(header))
+	}
+	var size int
+	if _, err := fmt.Sscan(fields[2], &size); err != nil {
+		return nil, fmt.Errorf("unexpected object header %q", strings.TrimSpace(header))
+	}
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(batch, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// git runs a git command in dir and returns its output
+func (g *Git) git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	return g.run(ctx, dir, nil, args...)
+}
+
+// run runs a git command in dir with the given input and returns its output.
+// Neither the system nor the global git configuration is read, so git does not
+// need a home directory. HTTP redirects are not followed, they could lead to
+// hosts the URL was not allowed for.
+func (g *Git) run(ctx context.Context, dir string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "http.followRedirects=false"}, args...)...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ALLOW_PROTOCOL="+allowedProtocols,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("git sources need the git command line in the controller image: %w", err)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
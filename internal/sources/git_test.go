@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("Git source", func() {
+	var (
+		work   string
+		bare   string
+		source *Git
+	)
+
+	run := func(dir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	commit := func(files map[string]string) string {
+		for name, data := range files {
+			Expect(os.MkdirAll(filepath.Join(work, filepath.Dir(name)), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(work, name), []byte(data), 0o644)).To(Succeed())
+		}
+		run(work, "add", "-A")
+		run(work, "commit", "-q", "-m", "update")
+		run(work, "push", "-q", "origin", "HEAD:main")
+		return run(work, "rev-parse", "HEAD")
+	}
+
+	BeforeEach(func() {
+		root := GinkgoT().TempDir()
+		work = filepath.Join(root, "work")
+		bare = filepath.Join(root, "config.git")
+		run(root, "init", "-q", "--bare", "-b", "main", bare)
+		run(root, "clone", "-q", bare, work)
+		source = NewGit(filepath.Join(root, "cache"))
+	})
+
+	It("should read the files under the path at the default branch", func() {
+		revision := commit(map[string]string{
+			"README.md":               "docs",
+			"config/app.properties":   "color=blue",
+			"config/nested/log.level": "info",
+		})
+
+		content, err := source.Fetch(context.Background(), "syncer", syncv1alpha1.GitSource{URL: "file://" + bare, Path: "config"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Revision).To(Equal(revision))
+		Expect(content.Data).To(Equal(map[string]string{
+			"app.properties":   "color=blue",
+			"nested_log.level": "info",
+		}))
+	})
+
+	It("should pin to a tag or a commit and pick up new commits on a branch", func() {
+		first := commit(map[string]string{"app.properties": "color=blue"})
+		run(work, "tag", "v1")
+		run(work, "push", "-q", "origin", "v1")
+		second := commit(map[string]string{"app.properties": "color=green"})
+
+		content, err := source.Fetch(context.Background(), "syncer", syncv1alpha1.GitSource{URL: bare, Tag: "v1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Revision).To(Equal(first))
+		Expect(content.Data).To(HaveKeyWithValue("app.properties", "color=blue"))
+
+		content, err = source.Fetch(context.Background(), "syncer", syncv1alpha1.GitSource{URL: bare, Commit: first[:12]})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Revision).To(Equal(first))
+
+		content, err = source.Fetch(context.Background(), "syncer", syncv1alpha1.GitSource{URL: bare, Branch: "main"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Revision).To(Equal(second))
+
+		third := commit(map[string]string{"app.properties": "color=red"})
+		content, err = source.Fetch(context.Background(), "syncer", syncv1alpha1.GitSource{URL: bare, Branch: "main"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Revision).To(Equal(third))
+		Expect(content.Data).To(HaveKeyWithValue("app.properties", "color=red"))
+	})
+
+	It("should fail for an unknown branch", func() {
+		commit(map[string]string{"app.properties": "color=blue"})
+		_, err := source.Fetch(context.Background(), "syncer", syncv1alpha1.GitSource{URL: bare, Branch: "missing"})
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("should remove a mirror once no owner uses its repository", func() {
+		commit(map[string]string{"app.properties": "color=blue"})
+		other := filepath.Join(filepath.Dir(bare), "other.git")
+		run(filepath.Dir(bare), "clone", "-q", "--bare", bare, other)
+
+		_, err := source.Fetch(context.Background(), "first", syncv1alpha1.GitSource{URL: bare})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = source.Fetch(context.Background(), "second", syncv1alpha1.GitSource{URL: bare})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source.mirrorDir(bare)).To(BeADirectory())
+
+		By("keeping the mirror while another owner uses it")
+		_, err = source.Fetch(context.Background(), "first", syncv1alpha1.GitSource{URL: other})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source.mirrorDir(bare)).To(BeADirectory())
+
+		By("removing it once the last owner is released")
+		source.Release("second")
+		Expect(source.mirrorDir(bare)).NotTo(BeAnExistingFile())
+		Expect(source.mirrorDir(other)).To(BeADirectory())
+		source.Release("first")
+		Expect(source.mirrorDir(other)).NotTo(BeAnExistingFile())
+		Expect(source.repos).To(BeEmpty())
+		Expect(source.owners).To(BeEmpty())
+	})
+
+	DescribeTable("GitHost",
+		func(repoURL, host string) {
+			actual, err := GitHost(repoURL)
+			if host == "" {
+				Expect(err).To(MatchError(ContainSubstring("is not a remote repository")))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actual).To(Equal(host))
+			}
+		},
+		Entry("https", "https://github.com/example/config.git", "github.com"),
+		Entry("ssh with user and port", "ssh://git@git.example.com:2222/config.git", "git.example.com:2222"),
+		Entry("git protocol", "git://git.example.com/config.git", "git.example.com"),
+		Entry("scp-like", "git@github.com:example/config.git", "github.com"),
+		Entry("scp-like without user", "git.example.com:config.git", "git.example.com"),
+		Entry("file URL", "file:///srv/config.git", ""),
+		Entry("absolute path", "/srv/config.git", ""),
+		Entry("relative path with a colon", "./repos/a:b", ""),
+		Entry("path without a colon", "config.git", ""),
+		Entry("remote helper", "ext::sh -c id", ""),
+		Entry("option as host", "ssh://-oProxyCommand=id/config.git", ""),
+		Entry("unknown scheme", "ftp://example.com/config.git", ""),
+	)
+})
@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	// httpTimeout bounds a single request to an HTTP source
+	httpTimeout = 30 * time.Second
+
+	// maxDocumentSize is the size of the largest document that still fits into a ConfigMap
+	maxDocumentSize = 1 << 20
+
+	// maxCachedDocuments bounds the number of documents kept for conditional
+	// requests. Beyond it, an arbitrary document is dropped and fetched in full
+	// the next time.
+	maxCachedDocuments = 256
+
+	// maxRedirects is the number of redirects followed for a single request
+	maxRedirects = 10
+)
+
+// HTTPAuth holds the credentials for an HTTP source. A bearer token takes
+// precedence over basic auth.
+type HTTPAuth struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// fingerprint identifies the credentials without keeping them around as a cache key
+func (a *HTTPAuth) fingerprint() string {
+	if a == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(a.Token + "\x00" + a.Username + "\x00" + a.Password))
+	return hex.EncodeToString(sum[:])
+}
+
+// document is the last response of an endpoint, kept for conditional requests
+type document struct {
+	// auth is the fingerprint of the credentials the document was fetched with
+	auth         string
+	etag         string
+	lastModified string
+	body         []byte
+	revision     string
+}
+
+// HTTP reads documents from HTTP(S) endpoints. Responses are cached per URL
+// and revalidated with If-None-Match and If-Modified-Since, so unchanged
+// documents are not transferred again. Redirects are only followed on the same
+// host. It is safe for concurrent use.
+type HTTP struct {
+	// Client sends the requests
+	Client *http.Client
+
+	mu        sync.Mutex
+	documents map[string]*document
+}
+
+// NewHTTP returns an HTTP source using a client with a request timeout
+func NewHTTP() *HTTP {
+	return &HTTP{
+		Client:    &http.Client{Timeout: httpTimeout, CheckRedirect: sameHostRedirect},
+		documents: map[string]*document{},
+	}
+}
+
+// sameHostRedirect refuses redirects to other hosts than the one of the source
+func sameHostRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Host != via[0].URL.Host {
+		return fmt.Errorf("refusing redirect from %s to another host %s", via[0].URL.Host, req.URL.Host)
+	}
+	return nil
+}
+
+// Forget drops the cached document of the URL
+func (h *HTTP) Forget(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.documents, url)
+}
+
+// Fetch reads and parses the document of the source
+func (h *HTTP) Fetch(ctx context.Context, source syncv1alpha1.HTTPSource, auth *HTTPAuth) (*Content, error) {
+	doc, err := h.get(ctx, source.URL, auth)
+	if err != nil {
+		return nil, err
+	}
+	return parse(source.Format, source.Key, doc.body, doc.revision)
+}
+
+// get returns the current document at url, revalidating a cached copy
+func (h *HTTP) get(ctx context.Context, url string, auth *HTTPAuth) (*document, error) {
+	fingerprint := auth.fingerprint()
+	h.mu.Lock()
+	cached := h.documents[url]
+	h.mu.Unlock()
+	// A document fetched with other credentials is not revalidated
+	if cached != nil && cached.auth != fingerprint {
+		cached = nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case auth == nil:
+	case auth.Token != "":
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case auth.Username != "":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch %s: unexpected status %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if len(body) > maxDocumentSize {
+		return nil, fmt.Errorf("document at %s exceeds %d bytes", url, maxDocumentSize)
+	}
+
+	doc := &document{
+		auth:         fingerprint,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		body:         body,
+	}
+	doc.revision = doc.etag
+	if doc.revision == "" {
+		sum := sha256.Sum256(body)
+		doc.revision = hex.EncodeToString(sum[:8])
+	}
+
+	h.mu.Lock()
+	if _, ok := h.documents[url]; !ok && len(h.documents) >= maxCachedDocuments {
+		for evicted := range h.documents {
+			delete(h.documents, evicted)
+			break
+		}
+	}
+	h.documents[url] = doc
+	h.mu.Unlock()
+	return doc, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("HTTP source", func() {
+	var (
+		server   *httptest.Server
+		document atomic.Value
+		served   atomic.Int32
+		source   *HTTP
+	)
+
+	BeforeEach(func() {
+		document.Store(`{"color": "blue", "replicas": 3, "limits": {"cpu": "1"}}`)
+		served.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body := document.Load().(string)
+			etag := `"` + strconv.Itoa(len(body)) + `"`
+			w.Header().Set("ETag", etag)
+			if req.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			served.Add(1)
+			_, _ = w.Write([]byte(body))
+		}))
+		DeferCleanup(server.Close)
+		source = NewHTTP()
+	})
+
+	auth := &HTTPAuth{Token: "secret"}
+
+	It("should parse a JSON document and revalidate it with its ETag", func() {
+		spec := syncv1alpha1.HTTPSource{URL: server.URL, Format: FormatJSON}
+		content, err := source.Fetch(context.Background(), spec, auth)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Data).To(Equal(map[string]string{
+			"color":    "blue",
+			"replicas": "3",
+			"limits":   `{"cpu": "1"}`,
+		}))
+		Expect(content.Revision).To(Equal(`"56"`))
+
+		By("not transferring an unchanged document again")
+		content, err = source.Fetch(context.Background(), spec, auth)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Data).To(HaveKeyWithValue("color", "blue"))
+		Expect(served.Load()).To(Equal(int32(1)))
+
+		By("picking up a changed document")
+		document.Store(`{"colour": "green"}`)
+		content, err = source.Fetch(context.Background(), spec, auth)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Data).To(Equal(map[string]string{"colour": "green"}))
+		Expect(served.Load()).To(Equal(int32(2)))
+	})
+
+	It("should fail without valid credentials", func() {
+		_, err := source.Fetch(context.Background(), syncv1alpha1.HTTPSource{URL: server.URL}, nil)
+		Expect(err).To(MatchError(ContainSubstring("401")))
+	})
+
+	It("should not follow redirects to other hosts", func() {
+		redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+		DeferCleanup(redirect.Close)
+
+		_, err := source.Fetch(context.Background(), syncv1alpha1.HTTPSource{URL: redirect.URL}, auth)
+		Expect(err).To(MatchError(ContainSubstring("refusing redirect")))
+	})
+
+	It("should parse YAML, properties and raw documents", func() {
+		content, err := parse(FormatYAML, "", []byte("color: blue\nlimits:\n  cpu: \"1\"\n"), "r1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Data).To(Equal(map[string]string{"color": "blue", "limits": "cpu: \"1\"\n"}))
+
+		content, err = parse(FormatProperties, "", []byte("# comment\ncolor = blue\nsize: large\nmessage=hello \\\n  world\n"), "r1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Data).To(Equal(map[string]string{"color": "blue", "size": "large", "message": "hello world"}))
+
+		content, err = parse(FormatProperties, "", []byte(strings.Join([]string{
+			`a\.b=c\=d\:e`,
+			`path=C:\\dir\\`,
+			`next=1`,
+			`odd=x\\\`,
+			`  continued`,
+			`escaped\-key = tab\there\nnewline`,
+			`unicode=caf\u00e9 \uD83D\uDE00`,
+			`empty`,
+		}, "\n")), "r1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Data).To(Equal(map[string]string{
+			"a.b":         "c=d:e",
+			"path":        `C:\dir\`,
+			"next":        "1",
+			"odd":         `x\continued`,
+			"escaped-key": "tab\there\nnewline",
+			"unicode":     "café 😀",
+			"empty":       "",
+		}))
+
+		_, err = parse(FormatProperties, "", []byte(`broken=\u12`), "r1")
+		Expect(err).To(MatchError(ContainSubstring("malformed")))
+
+		content, err = parse(FormatRaw, "app.json", []byte(`{"a": 1}`), "r1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Data).To(Equal(map[string]string{"app.json": `{"a": 1}`}))
+
+		_, err = parse(FormatJSON, "", []byte(`[1, 2]`), "r1")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,393 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+const (
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// annotationTitle names the file a layer holds, as set by ORAS
+	annotationTitle = "org.opencontainers.image.title"
+
+	// maxManifestSize is the size of the largest manifest that is accepted
+	maxManifestSize = 4 << 20
+)
+
+// OCIAuth holds the registry credentials for an OCI source
+type OCIAuth struct {
+	Username string
+	Password string
+}
+
+// descriptor describes a blob in a manifest
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// manifest is the part of an image manifest needed to read its files
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Layers    []descriptor `json:"layers"`
+}
+
+// OCI pulls artifacts from OCI registries. Every layer becomes one file,
+// named by its title annotation, or is extracted when it is a tar archive.
+// Manifests and blobs are verified against their digests. The last artifact
+// of every repository is cached, so only the manifest is fetched while a tag
+// does not move. Only blob downloads may be redirected to other hosts. It is
+// safe for concurrent use.
+type OCI struct {
+	// Client sends the requests
+	Client *http.Client
+
+	mu sync.Mutex
+	// artifacts holds the last artifact pulled from each repository, its
+	// revision is the digest of the manifest
+	artifacts map[string]*Content
+}
+
+// NewOCI returns an OCI source using a client with a request timeout
+func NewOCI() *OCI {
+	return &OCI{
+		Client:    &http.Client{Timeout: httpTimeout, CheckRedirect: blobRedirect},
+		artifacts: map[string]*Content{},
+	}
+}
+
+// blobRedirect lets registries hand blob downloads off to a storage host.
+// Blobs are verified against their digests, so nothing else the other host
+// serves ends up in a target. Everything else stays on the registry host.
+func blobRedirect(req *http.Request, via []*http.Request) error {
+	if strings.Contains(via[0].URL.Path, "/blobs/") && len(via) < maxRedirects {
+		return nil
+	}
+	return sameHostRedirect(req, via)
+}
+
+// Forget drops the cached artifact of the repository
+func (o *OCI) Forget(repository string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.artifacts, repository)
+}
+
+// registry is a repository in a registry together with its credentials
+type registry struct {
+	client     *http.Client
+	base       string
+	repository string
+	auth       *OCIAuth
+
+	// authorization is the header value that was accepted last
+	authorization string
+}
+
+// Fetch pulls the artifact of the source and returns its files. The revision
+// of the content is the digest of the manifest.
+func (o *OCI) Fetch(ctx context.Context, source syncv1alpha1.OCISource, auth *OCIAuth) (*Content, error) {
+	host, repository, ok := strings.Cut(source.Repository, "/")
+	if !ok || repository == "" {
+		return nil, fmt.Errorf("repository %q does not include a registry host", source.Repository)
+	}
+	scheme := "https"
+	if source.Insecure {
+		scheme = "http"
+	}
+	reg := &registry{client: o.Client, base: scheme + "://" + host, repository: repository, auth: auth}
+
+	reference := source.Digest
+	if reference == "" {
+		reference = source.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+
+	body, header, err := reg.get(ctx, "manifests/"+reference, maxManifestSize,
+		strings.Join([]string{mediaTypeOCIManifest, mediaTypeDockerManifest, mediaTypeOCIIndex, mediaTypeDockerList}, ", "))
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256Digest(body)
+	if source.Digest != "" && digest != source.Digest {
+		return nil, fmt.Errorf("manifest digest %s does not match %s", digest, source.Digest)
+	}
+	if served := header.Get("Docker-Content-Digest"); served != "" && served != digest {
+		return nil, fmt.Errorf("manifest digest %s does not match the digest %s reported by the registry", digest, served)
+	}
+
+	o.mu.Lock()
+	cached := o.artifacts[source.Repository]
+	o.mu.Unlock()
+	if cached != nil && cached.Revision == digest {
+		return cached, nil
+	}
+
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	mediaType := m.MediaType
+	if mediaType == "" {
+		mediaType = header.Get("Content-Type")
+	}
+	if mediaType == mediaTypeOCIIndex || mediaType == mediaTypeDockerList {
+		return nil, fmt.Errorf("%s is an image index, reference a single artifact instead", source.Repository)
+	}
+
+	content := newContent(digest)
+	for _, layer := range m.Layers {
+		if err := reg.readLayer(ctx, layer, content); err != nil {
+			return nil, err
+		}
+	}
+
+	o.mu.Lock()
+	if _, ok := o.artifacts[source.Repository]; !ok && len(o.artifacts) >= maxCachedDocuments {
+		for evicted := range o.artifacts {
+			delete(o.artifacts, evicted)
+			break
+		}
+	}
+	o.artifacts[source.Repository] = content
+	o.mu.Unlock()
+	return content, nil
+}
+
+// readLayer downloads a layer, verifies it and adds its files to the content
+func (r *registry) readLayer(ctx context.Context, layer descriptor, content *Content) error {
+	if layer.Size > maxDocumentSize {
+		return fmt.Errorf("layer %s exceeds %d bytes", layer.Digest, maxDocumentSize)
+	}
+	data, _, err := r.get(ctx, "blobs/"+layer.Digest, maxDocumentSize, "")
+	if err != nil {
+		return err
+	}
+	if digest := sha256Digest(data); digest != layer.Digest {
+		return fmt.Errorf("layer digest %s does not match %s", digest, layer.Digest)
+	}
+
+	switch {
+	case strings.Contains(layer.MediaType, "tar"):
+		return extractTar(layer, data, content)
+	case layer.Annotations[annotationTitle] != "":
+		return content.add(path.Clean(layer.Annotations[annotationTitle]), data)
+	default:
+		return fmt.Errorf("layer %s is neither a tar archive nor has a %s annotation", layer.Digest, annotationTitle)
+	}
+}
+
+// extractTar adds the regular files of a tar layer, which may be gzip compressed
+func extractTar(layer descriptor, data []byte, content *Content) error {
+	var reader io.Reader = bytes.NewReader(data)
+	if strings.Contains(layer.MediaType, "gzip") || bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("layer %s: %w", layer.Digest, err)
+		}
+		reader = gz
+	}
+
+	archive := tar.NewReader(io.LimitReader(reader, maxDocumentSize+1))
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("layer %s: %w", layer.Digest, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		file, err := io.ReadAll(archive)
+		if err != nil {
+			return fmt.Errorf("layer %s: %w", layer.Digest, err)
+		}
+		if err := content.add(name, file); err != nil {
+			return err
+		}
+	}
+}
+
+// get reads a manifest or blob of the repository, authenticating when the
+// registry asks for it
+func (r *registry) get(ctx context.Context, resource string, limit int64, accept string) ([]byte, http.Header, error) {
+	target := fmt.Sprintf("%s/v2/%s/%s", r.base, r.repository, resource)
+	resp, err := r.do(ctx, target, accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		if err := r.authenticate(ctx, challenge); err != nil {
+			return nil, nil, err
+		}
+		if resp, err = r.do(ctx, target, accept); err != nil {
+			return nil, nil, err
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to fetch %s: unexpected status %s", target, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	if int64(len(body)) > limit {
+		return nil, nil, fmt.Errorf("%s exceeds %d bytes", target, limit)
+	}
+	return body, resp.Header, nil
+}
+
+// do sends a single GET request with the current authorization
+func (r *registry) do(ctx context.Context, target, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if r.authorization != "" {
+		req.Header.Set("Authorization", r.authorization)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", target, err)
+	}
+	return resp, nil
+}
+
+// authenticate answers a WWW-Authenticate challenge, either with basic auth or
+// with a bearer token from the token service the registry points at
+func (r *registry) authenticate(ctx context.Context, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if r.auth == nil {
+			return fmt.Errorf("registry %s requires credentials", r.base)
+		}
+		r.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(r.auth.Username+":"+r.auth.Password))
+		return nil
+
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return fmt.Errorf("registry %s sent an invalid token realm %q", r.base, params["realm"])
+		}
+		query := realm.Query()
+		if service := params["service"]; service != "" {
+			query.Set("service", service)
+		}
+		query.Set("scope", fmt.Sprintf("repository:%s:pull", r.repository))
+		realm.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return err
+		}
+		if r.auth != nil {
+			req.SetBasicAuth(r.auth.Username, r.auth.Password)
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to get a token for %s: %w", r.base, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to get a token for %s: unexpected status %s", r.base, resp.Status)
+		}
+
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&token); err != nil {
+			return fmt.Errorf("invalid token response from %s: %w", realm.Host, err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		r.authorization = "Bearer " + token.Token
+		return nil
+
+	default:
+		return fmt.Errorf("registry %s requires unsupported authentication %q", r.base, challenge)
+	}
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and parameters
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var pair string
+		rest = strings.TrimLeft(rest, " ,")
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				break
+			}
+			pair, rest = value[1:end+1], value[end+2:]
+		} else {
+			pair, rest, _ = strings.Cut(value, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = pair
+	}
+	return scheme, params
+}
+
+// sha256Digest returns the OCI digest of data
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+// testRegistry is an in-process registry serving a single repository. It
+// hands out bearer tokens for the configured credentials.
+type testRegistry struct {
+	manifests map[string][]byte
+	blobs     map[string][]byte
+}
+
+func (t *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if user, password, ok := req.BasicAuth(); !ok || user != "puller" || password != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "pull-token"})
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer pull-token" {
+		w.Header().Set("WWW-Authenticate",
+			`Bearer realm="http://`+req.Host+`/token",service="test-registry",scope="repository:team/config:pull"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/v2/team/config/manifests/"):
+		body, ok := t.manifests[strings.TrimPrefix(req.URL.Path, "/v2/team/config/manifests/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", mediaTypeOCIManifest)
+		w.Header().Set("Docker-Content-Digest", sha256Digest(body))
+		_, _ = w.Write(body)
+	case strings.HasPrefix(req.URL.Path, "/v2/team/config/blobs/"):
+		body, ok := t.blobs[strings.TrimPrefix(req.URL.Path, "/v2/team/config/blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// push stores an artifact with the given layers under the tag and returns its digest
+func (t *testRegistry) push(tag string, layers ...descriptor) string {
+	body, err := json.Marshal(manifest{MediaType: mediaTypeOCIManifest, Layers: layers})
+	Expect(err).NotTo(HaveOccurred())
+	digest := sha256Digest(body)
+	t.manifests[tag] = body
+	t.manifests[digest] = body
+	return digest
+}
+
+// blob stores a layer and returns its descriptor
+func (t *testRegistry) blob(mediaType string, data []byte, annotations map[string]string) descriptor {
+	digest := sha256Digest(data)
+	t.blobs[digest] = data
+	return descriptor{MediaType: mediaType, Digest: digest, Size: int64(len(data)), Annotations: annotations}
+}
+
+var _ = Describe("OCI source", func() {
+	var (
+		registry *testRegistry
+		server   *httptest.Server
+		source   *OCI
+		spec     syncv1alpha1.OCISource
+	)
+
+	auth := &OCIAuth{Username: "puller", Password: "s3cret"}
+
+	BeforeEach(func() {
+		registry = &testRegistry{manifests: map[string][]byte{}, blobs: map[string][]byte{}}
+		server = httptest.NewServer(registry)
+		DeferCleanup(server.Close)
+		source = NewOCI()
+		spec = syncv1alpha1.OCISource{
+			Repository: strings.TrimPrefix(server.URL, "http://") + "/team/config",
+			Insecure:   true,
+		}
+	})
+
+	tarball := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		archive := tar.NewWriter(gz)
+		for name, data := range files {
+			Expect(archive.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := archive.Write([]byte(data))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(archive.Close()).To(Succeed())
+		Expect(gz.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	It("should pull the files of an artifact by tag and by digest", func() {
+		digest := registry.push("v1",
+			registry.blob("application/vnd.example.config", []byte("color=blue"),
+				map[string]string{annotationTitle: "app.properties"}),
+			registry.blob("application/vnd.oci.image.layer.v1.tar+gzip",
+				tarball(map[string]string{"./logging/level": "info"}), nil),
+		)
+
+		spec.Tag = "v1"
+		content, err := source.Fetch(context.Background(), spec, auth)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Revision).To(Equal(digest))
+		Expect(content.Data).To(Equal(map[string]string{
+			"app.properties": "color=blue",
+			"logging_level":  "info",
+		}))
+
+		spec.Tag = ""
+		spec.Digest = digest
+		content, err = NewOCI().Fetch(context.Background(), spec, auth)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Revision).To(Equal(digest))
+	})
+
+	It("should reject content that does not match its digest", func() {
+		layer := registry.blob("application/vnd.example.config", []byte("color=blue"),
+			map[string]string{annotationTitle: "app.properties"})
+		registry.push("v1", layer)
+		registry.blobs[layer.Digest] = []byte("color=red")
+
+		spec.Tag = "v1"
+		_, err := source.Fetch(context.Background(), spec, auth)
+		Expect(err).To(MatchError(ContainSubstring("does not match")))
+
+		spec.Tag = ""
+		spec.Digest = "sha256:" + strings.Repeat("0", 64)
+		registry.manifests[spec.Digest] = registry.manifests["v1"]
+		_, err = source.Fetch(context.Background(), spec, auth)
+		Expect(err).To(MatchError(ContainSubstring("does not match")))
+	})
+
+	It("should fail without credentials", func() {
+		registry.push("v1")
+		spec.Tag = "v1"
+		_, err := source.Fetch(context.Background(), spec, nil)
+		Expect(err).To(MatchError(ContainSubstring("401")))
+	})
+})
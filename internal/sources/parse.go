@@ -0,0 +1,244 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FormatJSON is a JSON object whose top-level keys become keys
+	FormatJSON = "JSON"
+
+	// FormatYAML is a YAML mapping whose top-level keys become keys
+	FormatYAML = "YAML"
+
+	// FormatProperties is a Java properties file whose entries become keys
+	FormatProperties = "Properties"
+
+	// FormatRaw stores the whole document under a single key
+	FormatRaw = "Raw"
+)
+
+// parse turns a document into content. Scalars are stored as they are, nested
+// objects and lists are stored in the format of the document.
+func parse(format, key string, document []byte, revision string) (*Content, error) {
+	content := newContent(revision)
+
+	switch format {
+	case FormatRaw:
+		if key == "" {
+			return nil, fmt.Errorf("a key is required for the %s format", FormatRaw)
+		}
+		return content, content.add(key, document)
+
+	case FormatProperties:
+		entries, err := parseProperties(document)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range entries {
+			if err := content.add(k, []byte(v)); err != nil {
+				return nil, err
+			}
+		}
+		return content, nil
+
+	case FormatJSON, FormatYAML, "":
+		jsonDocument := document
+		if format == FormatYAML {
+			var err error
+			if jsonDocument, err = yaml.YAMLToJSON(document); err != nil {
+				return nil, fmt.Errorf("invalid YAML document: %w", err)
+			}
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(jsonDocument, &object); err != nil {
+			return nil, fmt.Errorf("document is not a %s object: %w", format, err)
+		}
+		for k, raw := range object {
+			value, err := formatValue(format, raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of %q: %w", k, err)
+			}
+			if err := content.add(k, value); err != nil {
+				return nil, err
+			}
+		}
+		return content, nil
+
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// formatValue returns a string as is and anything else encoded in the format
+// of the document
+func formatValue(format string, raw json.RawMessage) ([]byte, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []byte(s), nil
+	}
+	trimmed := bytes.TrimSpace(raw)
+	if format != FormatYAML || (len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != '[') {
+		// Numbers, booleans and null read the same in both formats
+		return trimmed, nil
+	}
+	return yaml.JSONToYAML(raw)
+}
+
+// parseProperties reads the entries of a Java properties file. Keys and
+// values are separated by '=', ':' or whitespace, lines starting with '#' or
+// '!' are comments and a line ending in an odd number of backslashes continues
+// on the next line. Escapes such as \=, \:, \n and \uXXXX are decoded.
+func parseProperties(document []byte) (map[string]string, error) {
+	entries := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(document))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(document)+1)
+
+	var logical strings.Builder
+	continued := false
+	add := func() error {
+		key, value, err := splitProperty(logical.String())
+		logical.Reset()
+		if err != nil {
+			return fmt.Errorf("invalid properties document: %w", err)
+		}
+		entries[key] = value
+		return nil
+	}
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if !continued && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		// An even number of trailing backslashes are escaped backslashes
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		if continued = trailing%2 == 1; continued {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+		if err := add(); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid properties document: %w", err)
+	}
+	if continued {
+		if err := add(); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// splitProperty splits a logical line into its decoded key and value. The key
+// ends at the first '=', ':' or whitespace that is not escaped.
+func splitProperty(entry string) (string, string, error) {
+	end := len(entry)
+	for i := 0; i < len(entry); i++ {
+		if entry[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", entry[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	value := strings.TrimLeft(entry[end:], " \t\f")
+	if value != "" && (value[0] == '=' || value[0] == ':') {
+		value = strings.TrimLeft(value[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(entry[:end])
+	if err != nil {
+		return "", "", err
+	}
+	if value, err = unescapeProperty(value); err != nil {
+		return "", "", fmt.Errorf("value of %q: %w", key, err)
+	}
+	return key, value, nil
+}
+
+// unescapeProperty decodes the escapes of a key or value. A backslash before
+// any other character than t, n, r, f or u stands for the character itself.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, err := unicodeEscape(s[i+1:])
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// Characters outside the BMP are written as a pair of surrogates
+			if utf16.IsSurrogate(r) && strings.HasPrefix(s[i+1:], `\u`) {
+				if low, err := unicodeEscape(s[i+3:]); err == nil {
+					if pair := utf16.DecodeRune(r, low); pair != unicode.ReplacementChar {
+						r = pair
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// unicodeEscape decodes the four hex digits at the start of s
+func unicodeEscape(s string) (rune, error) {
+	if len(s) < 4 {
+		return 0, fmt.Errorf("malformed \\uXXXX escape")
+	}
+	r, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\uXXXX escape \\u%s", s[:4])
+	}
+	return rune(r), nil
+}
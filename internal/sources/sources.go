@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sources fetches master content from sources outside the cluster.
+package sources
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Content is the master content read from a source
+type Content struct {
+	// Data holds the UTF-8 files
+	Data map[string]string
+
+	// BinaryData holds the files that are not valid UTF-8
+	BinaryData map[string][]byte
+
+	// Revision identifies the content, e.g. a commit
+	Revision string
+}
+
+// newContent returns empty content with the given revision
+func newContent(revision string) *Content {
+	return &Content{Data: map[string]string{}, BinaryData: map[string][]byte{}, Revision: revision}
+}
+
+// add stores a file under the given path. Slashes in the path are replaced by
+// underscores, since ConfigMap keys cannot contain them.
+func (c *Content) add(path string, data []byte) error {
+	key := strings.ReplaceAll(path, "/", "_")
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		return fmt.Errorf("file %q cannot be used as ConfigMap key: %s", path, strings.Join(errs, ", "))
+	}
+	if _, ok := c.Data[key]; ok {
+		return fmt.Errorf("file %q maps to the duplicate key %q", path, key)
+	}
+	if _, ok := c.BinaryData[key]; ok {
+		return fmt.Errorf("file %q maps to the duplicate key %q", path, key)
+	}
+
+	if utf8.Valid(data) {
+		c.Data[key] = string(data)
+	} else {
+		c.BinaryData[key] = data
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+	"github.com/devShahriar/configmap-sync-controller/internal/sources"
+)
+
+// log is for logging in this package.
+var configmapsyncerlog = logf.Log.WithName("configmapsyncer-resource")
+
+// SetupConfigMapSyncerWebhookWithManager registers the webhook for ConfigMapSyncer in the manager.
+func SetupConfigMapSyncerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&syncv1alpha1.ConfigMapSyncer{}).
+		WithValidator(&ConfigMapSyncerCustomValidator{}).
+		WithDefaulter(&ConfigMapSyncerCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-sync-conf-sync-com-v1alpha1-configmapsyncer,mutating=true,failurePolicy=fail,sideEffects=None,groups=sync.conf-sync.com,resources=configmapsyncers,verbs=create;update,versions=v1alpha1,name=mconfigmapsyncer-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ConfigMapSyncerCustomDefaulter records the user who created a ConfigMapSyncer or
+// last changed its spec in the requester annotation. The controller authorizes
+// writes to targets in other namespaces on behalf of that user.
+type ConfigMapSyncerCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &ConfigMapSyncerCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type ConfigMapSyncer.
+func (d *ConfigMapSyncerCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	configMapSyncer, ok := obj.(*syncv1alpha1.ConfigMapSyncer)
+	if !ok {
+		return fmt.Errorf("expected a ConfigMapSyncer object but got %T", obj)
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Updates that leave the spec alone, such as the controller adding its
+	// finalizer, keep the previous requester. Users cannot set it themselves.
+	if req.Operation == admissionv1.Update {
+		old := &syncv1alpha1.ConfigMapSyncer{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return fmt.Errorf("failed to decode the old ConfigMapSyncer: %w", err)
+		}
+		if equality.Semantic.DeepEqual(old.Spec, configMapSyncer.Spec) {
+			setRequester(configMapSyncer, old.Annotations[syncv1alpha1.RequesterAnnotation])
+			return nil
+		}
+	}
+
+	requester, err := json.Marshal(req.UserInfo)
+	if err != nil {
+		return fmt.Errorf("failed to encode the requester: %w", err)
+	}
+	configmapsyncerlog.Info("Recording requester of ConfigMapSyncer", "name", configMapSyncer.GetName(),
+		"requester", req.UserInfo.Username)
+	setRequester(configMapSyncer, string(requester))
+	return nil
+}
+
+// setRequester sets the requester annotation, an empty value removes it
+func setRequester(configMapSyncer *syncv1alpha1.ConfigMapSyncer, requester string) {
+	if requester == "" {
+		delete(configMapSyncer.Annotations, syncv1alpha1.RequesterAnnotation)
+		return
+	}
+	if configMapSyncer.Annotations == nil {
+		configMapSyncer.Annotations = make(map[string]string)
+	}
+	configMapSyncer.Annotations[syncv1alpha1.RequesterAnnotation] = requester
+}
+
+// +kubebuilder:webhook:path=/validate-sync-conf-sync-com-v1alpha1-configmapsyncer,mutating=false,failurePolicy=fail,sideEffects=None,groups=sync.conf-sync.com,resources=configmapsyncers,verbs=create;update,versions=v1alpha1,name=vconfigmapsyncer-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ConfigMapSyncerCustomValidator validates ConfigMapSyncers when they are created or updated.
+// The reconciler enforces the same rules, the webhook rejects violations before they are stored.
+type ConfigMapSyncerCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ConfigMapSyncerCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ConfigMapSyncer.
+func (v *ConfigMapSyncerCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	configMapSyncer, ok := obj.(*syncv1alpha1.ConfigMapSyncer)
+	if !ok {
+		return nil, fmt.Errorf("expected a ConfigMapSyncer object but got %T", obj)
+	}
+	configmapsyncerlog.Info("Validation for ConfigMapSyncer upon creation", "name", configMapSyncer.GetName())
+
+	return nil, validateConfigMapSyncer(configMapSyncer)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ConfigMapSyncer.
+func (v *ConfigMapSyncerCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	configMapSyncer, ok := newObj.(*syncv1alpha1.ConfigMapSyncer)
+	if !ok {
+		return nil, fmt.Errorf("expected a ConfigMapSyncer object for the newObj but got %T", newObj)
+	}
+	configmapsyncerlog.Info("Validation for ConfigMapSyncer upon update", "name", configMapSyncer.GetName())
+
+	return nil, validateConfigMapSyncer(configMapSyncer)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ConfigMapSyncer.
+func (v *ConfigMapSyncerCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateConfigMapSyncer rejects local masters and Secrets outside the
+// namespace of the ConfigMapSyncer. Only a ClusterConfigMapSyncer may read
+// them from other namespaces.
+func validateConfigMapSyncer(configMapSyncer *syncv1alpha1.ConfigMapSyncer) error {
+	var allErrs field.ErrorList
+
+	master := configMapSyncer.Spec.MasterConfigMap
+	if master.KubeconfigSecretRef != nil {
+		allErrs = append(allErrs, validateSecretNamespace(configMapSyncer,
+			field.NewPath("spec", "masterConfigMap", "kubeconfigSecretRef", "namespace"), master.KubeconfigSecretRef.Namespace)...)
+	} else if master.Namespace != configMapSyncer.Namespace {
+		allErrs = append(allErrs, field.Forbidden(
+			field.NewPath("spec", "masterConfigMap", "namespace"),
+			fmt.Sprintf("a ConfigMapSyncer may only use a master ConfigMap from its own namespace %q, "+
+				"use a ClusterConfigMapSyncer to sync from %q", configMapSyncer.Namespace, master.Namespace),
+		))
+	}
+
+	if source := configMapSyncer.Spec.Source; source != nil && source.Git != nil {
+		if _, err := sources.GitHost(source.Git.URL); err != nil {
+			allErrs = append(allErrs, field.Forbidden(
+				field.NewPath("spec", "source", "git", "url"),
+				fmt.Sprintf("a ConfigMapSyncer may only read remote repositories, use a ClusterConfigMapSyncer instead: %v", err),
+			))
+		}
+	}
+	if source := configMapSyncer.Spec.Source; source != nil && source.HTTP != nil && source.HTTP.SecretRef != nil {
+		allErrs = append(allErrs, validateSecretNamespace(configMapSyncer,
+			field.NewPath("spec", "source", "http", "secretRef", "namespace"), source.HTTP.SecretRef.Namespace)...)
+	}
+	if source := configMapSyncer.Spec.Source; source != nil && source.OCI != nil && source.OCI.SecretRef != nil {
+		allErrs = append(allErrs, validateSecretNamespace(configMapSyncer,
+			field.NewPath("spec", "source", "oci", "secretRef", "namespace"), source.OCI.SecretRef.Namespace)...)
+	}
+
+	for i, cluster := range configMapSyncer.Spec.Clusters {
+		allErrs = append(allErrs, validateSecretNamespace(configMapSyncer,
+			field.NewPath("spec", "clusters").Index(i).Child("kubeconfigSecretRef", "namespace"), cluster.KubeconfigSecretRef.Namespace)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		syncv1alpha1.GroupVersion.WithKind("ConfigMapSyncer").GroupKind(),
+		configMapSyncer.Name, allErrs)
+}
+
+// validateSecretNamespace rejects Secrets outside the namespace of the ConfigMapSyncer
+func validateSecretNamespace(
+	configMapSyncer *syncv1alpha1.ConfigMapSyncer,
+	path *field.Path,
+	namespace string,
+) field.ErrorList {
+	if namespace == "" || namespace == configMapSyncer.Namespace {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(path,
+		fmt.Sprintf("a ConfigMapSyncer may only use Secrets from its own namespace %q", configMapSyncer.Namespace))}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	syncv1alpha1 "github.com/devShahriar/configmap-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("ConfigMapSyncer Webhook", func() {
+	var (
+		obj       *syncv1alpha1.ConfigMapSyncer
+		validator ConfigMapSyncerCustomValidator
+	)
+
+	BeforeEach(func() {
+		obj = &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-config-syncer", Namespace: "team-a"},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap: syncv1alpha1.ConfigMapReference{Name: "app-config", Namespace: "team-a"},
+			},
+		}
+		validator = ConfigMapSyncerCustomValidator{}
+	})
+
+	It("should admit a master in the namespace of the ConfigMapSyncer", func() {
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny a master in another namespace on creation", func() {
+		obj.Spec.MasterConfigMap.Namespace = "kube-system"
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.masterConfigMap.namespace"))
+	})
+
+	It("should deny moving the master to another namespace on update", func() {
+		updated := obj.DeepCopy()
+		updated.Spec.MasterConfigMap.Namespace = "kube-system"
+		_, err := validator.ValidateUpdate(context.Background(), obj, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should deny a kubeconfig Secret in another namespace", func() {
+		obj.Spec.Clusters = []syncv1alpha1.ClusterReference{{
+			Name:                "edge",
+			KubeconfigSecretRef: syncv1alpha1.SecretKeyReference{Name: "edge-kubeconfig", Namespace: "kube-system"},
+		}}
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.clusters[0].kubeconfigSecretRef.namespace"))
+	})
+
+	It("should deny a local Git repository", func() {
+		obj.Spec.Source = &syncv1alpha1.MasterSource{Git: &syncv1alpha1.GitSource{URL: "file:///srv/config.git"}}
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.source.git.url"))
+	})
+
+	It("should admit a master in any namespace of a remote cluster", func() {
+		obj.Spec.MasterConfigMap.Namespace = "config"
+		obj.Spec.MasterConfigMap.KubeconfigSecretRef = &syncv1alpha1.SecretKeyReference{Name: "config-cluster"}
+		_, err := validator.ValidateCreate(context.Background(), obj)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("ConfigMapSyncer Defaulting Webhook", func() {
+	var (
+		obj       *syncv1alpha1.ConfigMapSyncer
+		defaulter ConfigMapSyncerCustomDefaulter
+	)
+
+	requestContext := func(operation admissionv1.Operation, username string, old *syncv1alpha1.ConfigMapSyncer) context.Context {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			UserInfo:  authenticationv1.UserInfo{Username: username},
+		}}
+		if old != nil {
+			raw, err := json.Marshal(old)
+			Expect(err).NotTo(HaveOccurred())
+			req.OldObject = runtime.RawExtension{Raw: raw}
+		}
+		return admission.NewContextWithRequest(context.Background(), req)
+	}
+
+	BeforeEach(func() {
+		obj = &syncv1alpha1.ConfigMapSyncer{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-config-syncer", Namespace: "team-a"},
+			Spec: syncv1alpha1.ConfigMapSyncerSpec{
+				MasterConfigMap: syncv1alpha1.ConfigMapReference{Name: "app-config", Namespace: "team-a"},
+			},
+		}
+		defaulter = ConfigMapSyncerCustomDefaulter{}
+	})
+
+	It("should record the requester on creation", func() {
+		obj.Annotations = map[string]string{syncv1alpha1.RequesterAnnotation: `{"username":"system:admin"}`}
+		Expect(defaulter.Default(requestContext(admissionv1.Create, "alice", nil), obj)).To(Succeed())
+		Expect(syncv1alpha1.GetRequester(obj).Username).To(Equal("alice"))
+	})
+
+	It("should keep the requester when the spec does not change", func() {
+		Expect(defaulter.Default(requestContext(admissionv1.Create, "alice", nil), obj)).To(Succeed())
+		old := obj.DeepCopy()
+
+		obj.Finalizers = []string{"configmapsyncer.conf-sync.com/finalizer"}
+		obj.Annotations[syncv1alpha1.RequesterAnnotation] = `{"username":"system:admin"}`
+		Expect(defaulter.Default(requestContext(admissionv1.Update, "controller", old), obj)).To(Succeed())
+		Expect(syncv1alpha1.GetRequester(obj).Username).To(Equal("alice"))
+	})
+
+	It("should record the user who changed the spec", func() {
+		Expect(defaulter.Default(requestContext(admissionv1.Create, "alice", nil), obj)).To(Succeed())
+		old := obj.DeepCopy()
+
+		obj.Spec.TargetNamespaces = []string{"team-b"}
+		Expect(defaulter.Default(requestContext(admissionv1.Update, "bob", old), obj)).To(Succeed())
+		Expect(syncv1alpha1.GetRequester(obj).Username).To(Equal("bob"))
+	})
+})
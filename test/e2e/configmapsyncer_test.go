@@ -113,6 +113,11 @@ var _ = Describe("ConfigMapSyncer", Ordered, func() {
 				g.Expect(err).
 					NotTo(HaveOccurred(), fmt.Sprintf("Failed to create namespace %s", ns))
 			}, "30s", "5s").Should(Succeed())
+
+			// Namespaced syncers only write into namespaces that accept them
+			cmd := exec.Command("kubectl", "annotate", "namespace", ns, "conf-sync.com/accept-from="+sourceNamespace)
+			_, err := utils.Run(cmd)
+			Expect(err).NotTo(HaveOccurred(), fmt.Sprintf("Failed to annotate namespace %s", ns))
 		}
 
 		createNamespace(targetNamespace1)